@@ -0,0 +1,20 @@
+# Updates the key groups of all encrypted files below secrets/ to match the
+# creation rules of .sops.yaml, e.g. after adding a new team member, with
+# `terraform apply -invoke=action.sops_update_keys.secrets`.
+action "sops_update_keys" "secrets" {
+  config {
+    path = "${path.module}/secrets/**/*.sops.yaml"
+  }
+}
+
+# Alternatively, update the keys whenever the sops config file changes.
+resource "terraform_data" "sops_config" {
+  input = filesha256("${path.module}/.sops.yaml")
+
+  lifecycle {
+    action_trigger {
+      events  = [after_create, after_update]
+      actions = [action.sops_update_keys.secrets]
+    }
+  }
+}
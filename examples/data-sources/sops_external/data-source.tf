@@ -0,0 +1,13 @@
+data "http" "bundle" {
+  url = "https://raw.githubusercontent.com/nobbs/terraform-provider-sops/refs/heads/main/test/fixtures/basic.sops.json"
+}
+
+data "sops_external" "bundle" {
+  source     = data.http.bundle.response_body
+  input_type = "json"
+}
+
+output "abc" {
+  value     = data.sops_external.bundle.data["abc"]
+  sensitive = true
+}
@@ -0,0 +1,20 @@
+data "sops_file" "secrets" {
+  source_file = "secrets.sops.yaml"
+}
+
+# Nested keys are joined by dots, list elements are addressed by their index.
+output "db_password" {
+  value     = data.sops_file.secrets.data["database.password"]
+  sensitive = true
+}
+
+# Files with an extension that doesn't match their format need an explicit input type.
+data "sops_file" "certificate" {
+  source_file = "certificate.sops.pem"
+  input_type  = "raw"
+}
+
+output "certificate" {
+  value     = data.sops_file.certificate.raw
+  sensitive = true
+}
@@ -0,0 +1,15 @@
+ephemeral "sops_age_key" "ci" {
+  post_quantum = true
+}
+
+# The generated identity is encrypted for the age recipients of the team
+# without ever being stored in plan or state. Increment the version to rotate
+# the key.
+resource "sops_secret_file" "ci_key" {
+  filename           = "${path.module}/secrets/ci-key.sops.env"
+  content_wo         = "SOPS_AGE_KEY=${ephemeral.sops_age_key.ci.identity}\nSOPS_AGE_RECIPIENT=${ephemeral.sops_age_key.ci.recipient}\n"
+  content_wo_version = 1
+  age = [
+    "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn",
+  ]
+}
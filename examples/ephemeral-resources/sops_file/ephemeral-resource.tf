@@ -0,0 +1,11 @@
+ephemeral "sops_file" "database" {
+  file = "./../../../test/fixtures/complex.sops.yaml"
+}
+
+# The decrypted values can be passed to other ephemeral contexts, e.g. a
+# provider configuration, without ending up in plan or state.
+provider "postgresql" {
+  host     = "localhost"
+  username = "postgres"
+  password = ephemeral.sops_file.database.data.string_key
+}
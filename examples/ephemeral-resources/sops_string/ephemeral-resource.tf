@@ -0,0 +1,16 @@
+data "http" "bundle" {
+  url = "https://raw.githubusercontent.com/nobbs/terraform-provider-sops/refs/heads/main/test/fixtures/basic.sops.json"
+}
+
+ephemeral "sops_string" "bundle" {
+  content = data.http.bundle.response_body
+  format  = "json"
+}
+
+# The decrypted values can be passed to other ephemeral contexts, e.g. a
+# provider configuration, without ending up in plan or state.
+provider "postgresql" {
+  host     = "localhost"
+  username = "postgres"
+  password = ephemeral.sops_string.bundle.data.abc
+}
@@ -0,0 +1,10 @@
+ephemeral "sops_temp_file" "kubeconfig" {
+  file          = "./secrets/kubeconfig.sops.yaml"
+  output_format = "yaml"
+}
+
+# The temporary file only exists for the duration of the Terraform run and is
+# removed again once the ephemeral resource is closed.
+provider "kubernetes" {
+  config_path = ephemeral.sops_temp_file.kubeconfig.path
+}
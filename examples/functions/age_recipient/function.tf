@@ -0,0 +1,22 @@
+output "x25519" {
+  value = provider::sops::age_recipient(file("./../../../test/age.key"))
+}
+
+# x25519 = "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn"
+
+output "ssh-ed25519" {
+  value = provider::sops::age_recipient(file("./../../../test/ssh-ed25519.key"))
+}
+
+# ssh-ed25519 = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINvgHD0sU+I1aE6Voned+s/KdRqdrBwV9HWRRzt7fQ1i"
+
+# Encrypt for the identity held in a secret store, so that the recipient can
+# never drift from the key that is actually used to decrypt.
+data "vault_kv_secret_v2" "sops" {
+  mount = "secret"
+  name  = "sops/production"
+}
+
+locals {
+  recipient = nonsensitive(provider::sops::age_recipient(data.vault_kv_secret_v2.sops.data.identity))
+}
@@ -0,0 +1,64 @@
+output "basic-yaml" {
+  value = provider::sops::decrypt("./../../../test/fixtures/basic.sops.yaml", null)
+}
+
+# basic-yaml = {
+#   "data" = {
+#     "abc" = "xyz"
+#     "floats" = 3.14e-10
+#     "integers" = 123
+#     "truthy" = true
+#   }
+#   "expected_mac_present" = true
+#   "lastmodified" = "2024-11-27T20:58:06Z"
+#   "mac_valid" = true
+#   "raw" = <<-EOT
+#   abc: xyz
+#   integers: 123
+#   truthy: true
+#   floats: 3.14e-10
+#
+#   EOT
+# }
+
+output "inline-json" {
+  value = provider::sops::decrypt(file("./../../../test/fixtures/basic.sops.json"), {
+    source_type = "inline"
+    format      = "json"
+  })
+}
+
+output "as-dotenv" {
+  value = provider::sops::decrypt("./../../../test/fixtures/basic.sops.yaml", {
+    output_format = "dotenv"
+  })
+}
+
+# as-dotenv = {
+#   "data" = {
+#     "abc" = "xyz"
+#     "floats" = "3.14E-10"
+#     "integers" = "123"
+#     "truthy" = "true"
+#   }
+#   "raw" = <<-EOT
+#   abc=xyz
+#   integers=123
+#   truthy=true
+#   floats=3.14E-10
+#
+#   EOT
+# }
+
+locals {
+  tampered = provider::sops::decrypt("./../../../test/fixtures/basic-mac-mismatch.sops.yaml", {
+    ignore_mac = true
+  })
+}
+
+check "secrets_integrity" {
+  assert {
+    condition     = local.tampered.mac_valid
+    error_message = "The secrets file was modified outside of sops on ${local.tampered.lastmodified}."
+  }
+}
@@ -0,0 +1,14 @@
+output "nested-string" {
+  value = provider::sops::extract("./../../../test/fixtures/complex.sops.yaml", "[\"object_key\"][\"nested_string\"]")
+}
+
+# nested-string = "nested example"
+
+output "nested-object" {
+  value = provider::sops::extract("./../../../test/fixtures/complex.sops.yaml", "/object_key/nested_object")
+}
+
+# nested-object = {
+#   "deeper_boolean" = false
+#   "deeper_string" = "deeper example"
+# }
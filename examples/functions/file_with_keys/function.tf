@@ -0,0 +1,45 @@
+output "pgp" {
+  value = provider::sops::file_with_keys("./../../../test/fixtures/pgp.sops.yaml", {
+    pgp_armored = [file("./../../../test/pgp.key")]
+  })
+}
+
+# pgp = {
+#   "data" = {
+#     "abc" = "xyz"
+#     "floats" = 3.14e-10
+#     "integers" = 123
+#     "truthy" = true
+#   }
+#   "raw" = <<-EOT
+#   abc: xyz
+#   integers: 123
+#   truthy: true
+#   floats: 3.14e-10
+#
+#   EOT
+# }
+
+# Decrypt the secrets of two teams in the same run, each with the key taken
+# from their own secret store.
+ephemeral "vault_kv_secret_v2" "payments" {
+  mount = "payments"
+  name  = "sops"
+}
+
+ephemeral "vault_kv_secret_v2" "checkout" {
+  mount = "checkout"
+  name  = "sops"
+}
+
+provider "postgresql" {
+  password = provider::sops::file_with_keys("./secrets/payments.sops.yaml", {
+    age = [ephemeral.vault_kv_secret_v2.payments.data.age_identity]
+  }).data.database_password
+}
+
+provider "stripe" {
+  api_key = provider::sops::file_with_keys("./secrets/checkout.sops.yaml", {
+    age = [ephemeral.vault_kv_secret_v2.checkout.data.age_identity]
+  }).data.stripe_api_key
+}
@@ -0,0 +1,21 @@
+output "basic-yaml" {
+  value = provider::sops::is_encrypted("./../../../test/fixtures/basic.sops.yaml", null)
+}
+
+# basic-yaml = {
+#   "encrypted" = true
+#   "format" = "yaml"
+# }
+
+variable "config" {
+  description = "Either plaintext YAML or sops encrypted YAML."
+  type        = string
+}
+
+locals {
+  config = (
+    provider::sops::is_encrypted(var.config, { source_type = "inline", format = "yaml" }).encrypted
+    ? provider::sops::decrypt(var.config, { source_type = "inline", format = "yaml" }).data
+    : yamldecode(var.config)
+  )
+}
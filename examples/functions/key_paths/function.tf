@@ -0,0 +1,26 @@
+output "basic-yaml" {
+  value = provider::sops::key_paths("./../../../test/fixtures/basic.sops.yaml")
+}
+
+# basic-yaml = tolist([
+#   {
+#     "document" = 0
+#     "encrypted" = true
+#     "extract_path" = "[\"abc\"]"
+#     "path" = "abc"
+#     "type" = "str"
+#   },
+#   ...
+# ])
+
+locals {
+  required_keys = ["abc", "integers"]
+  present_keys  = provider::sops::key_paths("./../../../test/fixtures/basic.sops.yaml")[*].path
+}
+
+check "required_keys_present" {
+  assert {
+    condition     = length(setsubtract(local.required_keys, local.present_keys)) == 0
+    error_message = "The secrets file is missing the keys: ${join(", ", setsubtract(local.required_keys, local.present_keys))}"
+  }
+}
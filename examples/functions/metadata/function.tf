@@ -0,0 +1,35 @@
+output "basic-yaml" {
+  value = provider::sops::metadata("./../../../test/fixtures/basic.sops.yaml")
+}
+
+# basic-yaml = {
+#   "encrypted_comment_regex" = tostring(null)
+#   "encrypted_regex" = tostring(null)
+#   "encrypted_suffix" = tostring(null)
+#   "key_groups" = tolist([
+#     tolist([
+#       {
+#         "identifier" = "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn"
+#         "type" = "age"
+#       },
+#     ]),
+#   ])
+#   "lastmodified" = "2024-11-27T20:58:06Z"
+#   "mac_only_encrypted" = false
+#   "mac_present" = true
+#   "shamir_threshold" = 0
+#   "unencrypted_comment_regex" = tostring(null)
+#   "unencrypted_regex" = tostring(null)
+#   "unencrypted_suffix" = "_unencrypted"
+#   "version" = "3.9.1"
+# }
+
+check "secrets_encrypted_for_ci" {
+  assert {
+    condition = contains(
+      flatten(provider::sops::metadata("./../../../test/fixtures/basic.sops.yaml").key_groups)[*].identifier,
+      "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn",
+    )
+    error_message = "The secrets file is not encrypted for the CI age recipient."
+  }
+}
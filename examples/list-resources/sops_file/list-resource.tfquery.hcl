@@ -0,0 +1,11 @@
+# Lists all encrypted YAML files of the repository, e.g. with
+# `terraform query -generate-config-out=secrets.tf` to import them.
+list "sops_file" "secrets" {
+  provider = sops
+
+  config {
+    directory = "."
+    include   = ["**/*.sops.yaml"]
+    exclude   = [".terraform/**"]
+  }
+}
@@ -1,2 +1,32 @@
-# There are no configuration options
+# Without any configuration, the provider uses the same key sources as sops,
+# e.g. the SOPS_AGE_KEY_FILE environment variable.
 provider "sops" {}
+
+# Alternatively, age identities can be configured explicitly, e.g. from an
+# ephemeral resource of a secret store.
+provider "sops" {
+  alias        = "vault"
+  age_identity = ephemeral.vault_kv_secret_v2.age.data["identity"]
+}
+
+provider "sops" {
+  alias             = "file"
+  age_identity_file = "./keys/production.txt"
+}
+
+# SSH private keys can be used as age identities as well, including
+# passphrase-protected ones.
+provider "sops" {
+  alias                          = "ssh"
+  age_ssh_private_key_files      = [pathexpand("~/.ssh/id_ed25519")]
+  age_ssh_private_key_passphrase = var.ssh_key_passphrase
+}
+
+# Hardware-backed identities are supported through age plugins, which are run
+# non-interactively, so the token must not require a PIN.
+provider "sops" {
+  alias                  = "yubikey"
+  age_identity           = file("./keys/yubikey-identity.txt")
+  age_plugin_search_path = ["/opt/age-plugins/bin"]
+  age_plugin_timeout     = "1m"
+}
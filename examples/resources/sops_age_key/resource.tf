@@ -0,0 +1,19 @@
+# Generate a post-quantum key pair per environment and encrypt the secrets of
+# each environment for it.
+resource "sops_age_key" "environment" {
+  for_each = toset(["staging", "production"])
+
+  post_quantum = true
+}
+
+resource "local_file" "sops_config" {
+  filename = "${path.module}/.sops.yaml"
+  content = yamlencode({
+    creation_rules = [
+      for environment, key in sops_age_key.environment : {
+        path_regex = "^secrets/${environment}/.*"
+        age        = key.recipient
+      }
+    ]
+  })
+}
@@ -0,0 +1,10 @@
+# Rotates the data key of the production secrets every 90 days, or right away
+# whenever the incident number changes.
+resource "sops_data_key_rotation" "production" {
+  filename      = "${path.module}/secrets/production.sops.yaml"
+  rotation_days = 90
+
+  triggers = {
+    incident = "none"
+  }
+}
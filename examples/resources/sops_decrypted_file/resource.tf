@@ -0,0 +1,8 @@
+# Decrypt a secrets file for a tool that can only read plaintext files from
+# disk. The file is removed again on destroy.
+resource "sops_decrypted_file" "app_config" {
+  source          = "${path.module}/secrets/app.sops.yaml"
+  filename        = "${path.module}/.generated/app.json"
+  output_format   = "json"
+  file_permission = "0640"
+}
@@ -0,0 +1,31 @@
+resource "random_password" "database" {
+  length = 32
+}
+
+# Encrypt generated secrets for the age recipients of the team, so that the
+# file can be committed to git.
+resource "sops_encrypted_file" "database" {
+  filename = "${path.module}/secrets/database.sops.yaml"
+  content = yamlencode({
+    username = "app"
+    password = random_password.database.result
+  })
+  age = [
+    "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn",
+  ]
+}
+
+# Objects are converted into the format of the file. With key groups, the data
+# key is split between the groups and the given number of them is required to
+# decrypt the file.
+resource "sops_encrypted_file" "root_credentials" {
+  filename = "${path.module}/secrets/root.sops.json"
+  content_object = {
+    password = random_password.database.result
+  }
+  key_groups = [
+    { age = ["age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn"] },
+    { kms = ["arn:aws:kms:eu-central-1:123456789012:key/00000000-0000-0000-0000-000000000000"] },
+  ]
+  shamir_threshold = 2
+}
@@ -0,0 +1,6 @@
+import {
+  to = sops_file.database
+  identity = {
+    path = "secrets/database.sops.yaml"
+  }
+}
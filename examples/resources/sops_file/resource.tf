@@ -0,0 +1,9 @@
+# Tracks the recipients of an encrypted file, e.g. to verify that all secrets
+# of a repository are encrypted for the current team.
+resource "sops_file" "database" {
+  path = "${path.module}/secrets/database.sops.yaml"
+}
+
+output "database_recipients" {
+  value = sops_file.database.recipients[*].identifier
+}
@@ -0,0 +1,18 @@
+resource "random_password" "database" {
+  length = 32
+}
+
+# Add a generated password to an existing secrets file, which is otherwise
+# maintained by hand. The value is removed from the file again on destroy.
+resource "sops_file_value" "database_password" {
+  filename = "${path.module}/secrets/production.sops.yaml"
+  path     = "[\"database\"][\"password\"]"
+  value    = random_password.database.result
+}
+
+# Objects and lists can be set as well, and paths can be given as JSON Pointer.
+resource "sops_file_value" "database_hosts" {
+  filename = "${path.module}/secrets/production.sops.yaml"
+  path     = "/database/hosts"
+  value    = ["db-0.internal", "db-1.internal"]
+}
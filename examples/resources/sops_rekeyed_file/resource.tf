@@ -0,0 +1,15 @@
+# Hands the database credentials to a partner team, encrypted for their own
+# age key. Only the selected values are copied.
+resource "sops_rekeyed_file" "partner" {
+  source   = "${path.module}/secrets/production.sops.yaml"
+  filename = "${path.module}/partner/database.sops.yaml"
+
+  key_paths = [
+    "[\"database\"][\"user\"]",
+    "/database/password",
+  ]
+
+  age = [
+    "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn",
+  ]
+}
@@ -0,0 +1,14 @@
+ephemeral "random_password" "database" {
+  length = 32
+}
+
+# The generated password is encrypted for the age recipients of the team
+# without ever being stored in plan or state.
+resource "sops_secret_file" "database" {
+  filename           = "${path.module}/secrets/database.sops.env"
+  content_wo         = "PASSWORD=${ephemeral.random_password.database.result}\n"
+  content_wo_version = 1
+  age = [
+    "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn",
+  ]
+}
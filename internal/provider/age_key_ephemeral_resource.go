@@ -0,0 +1,88 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that ageKeyEphemeralResource implements the EphemeralResource interfaces.
+var _ ephemeral.EphemeralResource = &ageKeyEphemeralResource{}
+
+type ageKeyEphemeralResource struct{}
+
+type ageKeyEphemeralResourceModel struct {
+	PostQuantum types.Bool   `tfsdk:"post_quantum"`
+	Identity    types.String `tfsdk:"identity"`
+	Recipient   types.String `tfsdk:"recipient"`
+}
+
+func NewAgeKeyEphemeralResource() ephemeral.EphemeralResource {
+	return &ageKeyEphemeralResource{}
+}
+
+func (r *ageKeyEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_age_key"
+}
+
+func (r *ageKeyEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Generates an [age](https://age-encryption.org/) key pair, like ` + utils.Code("age-keygen") + `,
+			without persisting it in plan or state. A new key pair is generated on every run, so an
+			identity that is needed later has to be stored elsewhere, e.g. encrypted through the
+			write-only attribute of ` + utils.Code("sops_secret_file") + `. Use the ` + utils.Code("sops_age_key") + `
+			resource to generate a key pair that is kept in state.
+
+			Either a classic X25519 key pair or, with ` + utils.Code("post_quantum") + `, a post-quantum
+			hybrid ML-KEM-768 + X25519 key pair is generated.
+
+			Ephemeral resources are available in Terraform v1.10 and later.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"post_quantum": schema.BoolAttribute{
+				MarkdownDescription: "Whether to generate a post-quantum hybrid key pair instead of a classic X25519 key pair. Defaults to `false`.",
+				Optional:            true,
+			},
+			"identity": schema.StringAttribute{
+				MarkdownDescription: "The secret age identity, e.g. `AGE-SECRET-KEY-1...`.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"recipient": schema.StringAttribute{
+				MarkdownDescription: "The public age recipient of the identity, e.g. `age1...` or `age1pq1...`.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *ageKeyEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ageKeyEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := utils.GenerateAgeKey(data.PostQuantum.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to generate age key", fmt.Sprintf("failed to generate age key: %v", err))
+		return
+	}
+
+	data.Identity = types.StringValue(key.Identity)
+	data.Recipient = types.StringValue(key.Recipient)
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,132 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that ageKeyResource implements the Resource interfaces.
+var _ resource.Resource = &ageKeyResource{}
+
+type ageKeyResource struct{}
+
+type ageKeyResourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	PostQuantum types.Bool   `tfsdk:"post_quantum"`
+	Identity    types.String `tfsdk:"identity"`
+	Recipient   types.String `tfsdk:"recipient"`
+}
+
+func NewAgeKeyResource() resource.Resource {
+	return &ageKeyResource{}
+}
+
+func (r *ageKeyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_age_key"
+}
+
+func (r *ageKeyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Generates an [age](https://age-encryption.org/) key pair, like ` + utils.Code("age-keygen") + `,
+			e.g. to bootstrap a key per environment and pass its recipient to ` + utils.Code("sops_encrypted_file") + `
+			or a generated ` + utils.Code(".sops.yaml") + `. Either a classic X25519 key pair or, with
+			` + utils.Code("post_quantum") + `, a post-quantum hybrid ML-KEM-768 + X25519 key pair is
+			generated. Files encrypted for a post-quantum recipient can only be decrypted by tools
+			using age v1.3 or later.
+
+			~> **Note:** The identity is stored unencrypted in the Terraform state. Protect access to
+			Terraform state accordingly, or use the ` + utils.Code("sops_age_key") + ` ephemeral resource
+			if the identity does not need to outlive the run.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The recipient of the key pair.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"post_quantum": schema.BoolAttribute{
+				MarkdownDescription: "Whether to generate a post-quantum hybrid key pair instead of a classic X25519 key pair. Changing this generates a new key pair. Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"identity": schema.StringAttribute{
+				MarkdownDescription: "The secret age identity, e.g. `AGE-SECRET-KEY-1...`, which can be passed to the `age_identity` attribute of the provider.",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"recipient": schema.StringAttribute{
+				MarkdownDescription: "The public age recipient of the identity, e.g. `age1...` or `age1pq1...`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *ageKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ageKeyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := utils.GenerateAgeKey(data.PostQuantum.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to generate age key", fmt.Sprintf("failed to generate age key: %v", err))
+		return
+	}
+
+	data.ID = types.StringValue(key.Recipient)
+	data.Identity = types.StringValue(key.Identity)
+	data.Recipient = types.StringValue(key.Recipient)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ageKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// the key pair only exists in the state, so there is nothing to refresh
+}
+
+func (r *ageKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// all arguments require replacement, so there is nothing to update
+	var data ageKeyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ageKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// the key pair only exists in the state, so there is nothing to delete
+}
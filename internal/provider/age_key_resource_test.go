@@ -0,0 +1,43 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAgeKeyResource_basic(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "sops_age_key" "x25519" {}
+
+resource "sops_age_key" "post_quantum" {
+	post_quantum = true
+}
+
+# the generated recipients can be used to encrypt files right away
+resource "sops_encrypted_file" "test" {
+	filename = "${path.module}/secrets.sops.json"
+	content  = jsonencode({ password = "hunter2" })
+	age      = [sops_age_key.x25519.recipient, sops_age_key.post_quantum.recipient]
+}
+`,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("sops_age_key.x25519", "post_quantum", "false"),
+					resource.TestMatchResourceAttr("sops_age_key.x25519", "identity", regexp.MustCompile(`^AGE-SECRET-KEY-1[0-9A-Z]+$`)),
+					resource.TestMatchResourceAttr("sops_age_key.x25519", "recipient", regexp.MustCompile(`^age1[0-9a-z]{58}$`)),
+					resource.TestCheckResourceAttrPair("sops_age_key.x25519", "id", "sops_age_key.x25519", "recipient"),
+					resource.TestMatchResourceAttr("sops_age_key.post_quantum", "identity", regexp.MustCompile(`^AGE-SECRET-KEY-PQ-1[0-9A-Z]+$`)),
+					resource.TestMatchResourceAttr("sops_age_key.post_quantum", "recipient", regexp.MustCompile(`^age1pq1[0-9a-z]+$`)),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+var _ function.Function = &ageRecipientFunction{}
+
+type ageRecipientFunction struct{}
+
+func NewAgeRecipientFunction() function.Function {
+	return &ageRecipientFunction{}
+}
+
+func (f *ageRecipientFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "age_recipient"
+}
+
+func (f *ageRecipientFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Returns the age recipient of an age identity.",
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Returns the public [age](https://age-encryption.org/) recipient of an age identity, like
+			` + utils.Code("age-keygen -y") + `, e.g. to pass the recipient of an identity from a secret store
+			to ` + utils.Code("sops_encrypted_file") + ` or a generated ` + utils.Code(".sops.yaml") + `
+			without copying it by hand. The following identities are supported:
+
+			- X25519 identities, e.g. ` + utils.Code("AGE-SECRET-KEY-1...") + `, which return an ` +
+			utils.Code("age1...") + ` recipient.
+			- Post-quantum hybrid identities, e.g. ` + utils.Code("AGE-SECRET-KEY-PQ-1...") + `, which
+			  return an ` + utils.Code("age1pq1...") + ` recipient.
+			- SSH ed25519 and RSA private keys, which return the public key in the ` +
+			utils.Code("authorized_keys") + ` format without comment. The public key of
+			  passphrase-protected keys in the OpenSSH format is read without the passphrase.
+
+			The identity may also be the content of an age key file, including comments, as long as it
+			contains exactly one identity. If the identity is sensitive, Terraform marks the recipient
+			as sensitive as well. Use ` + utils.Code("nonsensitive()") + ` to reveal it.
+		`)),
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "identity",
+				MarkdownDescription: "The age identity or SSH private key.",
+			},
+		},
+
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ageRecipientFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var identity string
+
+	resp.Error = req.Arguments.Get(ctx, &identity)
+	if resp.Error != nil {
+		return
+	}
+
+	recipient, err := utils.AgeRecipient(identity)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, recipient)
+}
@@ -0,0 +1,287 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that dataKeyRotationResource implements the Resource interfaces.
+var _ resource.Resource = &dataKeyRotationResource{}
+var _ resource.ResourceWithConfigure = &dataKeyRotationResource{}
+var _ resource.ResourceWithModifyPlan = &dataKeyRotationResource{}
+var _ resource.ResourceWithValidateConfig = &dataKeyRotationResource{}
+
+type dataKeyRotationResource struct {
+	providerData *sopsProviderData
+}
+
+type dataKeyRotationResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	Filename        types.String `tfsdk:"filename"`
+	Format          types.String `tfsdk:"format"`
+	RotationDays    types.Int64  `tfsdk:"rotation_days"`
+	Triggers        types.Map    `tfsdk:"triggers"`
+	LastModified    types.String `tfsdk:"lastmodified"`
+	RotationRFC3339 types.String `tfsdk:"rotation_rfc3339"`
+}
+
+func NewDataKeyRotationResource() resource.Resource {
+	return &dataKeyRotationResource{}
+}
+
+func (r *dataKeyRotationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_data_key_rotation"
+}
+
+func (r *dataKeyRotationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Rotates the data key of an existing [sops](https://getsops.io/) encrypted file, like
+			` + utils.Code("sops rotate") + `. The file is decrypted in memory with the key material of the
+			provider and encrypted again with a freshly generated data key for the same recipients, so
+			its plaintext stays identical. The plaintext is never stored in plan or state.
+
+			The data key is rotated when the resource is created and replaced afterwards, either once
+			` + utils.Code("rotation_days") + ` have passed since the last rotation, similar to the
+			` + utils.Code("time_rotating") + ` resource of the time provider, or whenever
+			` + utils.Code("triggers") + ` change. Destroying the resource does not modify the file.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The path of the encrypted file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"filename": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops encrypted file to rotate the data key of.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "The format of the file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"rotation_days": schema.Int64Attribute{
+				MarkdownDescription: "The number of days after which the data key is rotated again. If not provided, the data key is only rotated again if `triggers` change.",
+				Optional:            true,
+			},
+			"triggers": schema.MapAttribute{
+				MarkdownDescription: "Arbitrary map of values that, when changed, rotates the data key again.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"lastmodified": schema.StringAttribute{
+				MarkdownDescription: "The time the data key was last rotated, as RFC3339 timestamp. This is the `lastmodified` value of the sops metadata of the file after the rotation.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rotation_rfc3339": schema.StringAttribute{
+				MarkdownDescription: "The time the data key is rotated again, as RFC3339 timestamp. Null if `rotation_days` is not set.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *dataKeyRotationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *dataKeyRotationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data dataKeyRotationResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.RotationDays.IsNull() && !data.RotationDays.IsUnknown() && data.RotationDays.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rotation_days"),
+			"Invalid rotation days",
+			fmt.Sprintf("rotation_days must be at least 1, got %d", data.RotationDays.ValueInt64()),
+		)
+	}
+
+	if format := data.Format.ValueString(); format != "" && !utils.IsValidFormat(format) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+	}
+}
+
+func (r *dataKeyRotationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan dataKeyRotationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// infer format from file extension if not explicitly provided, so that it is known during plan
+	if plan.Format.IsUnknown() && !plan.Filename.IsUnknown() {
+		format := utils.FileFormatFromPath(plan.Filename.ValueString())
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("format"), types.StringValue(format))...)
+	}
+}
+
+func (r *dataKeyRotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data dataKeyRotationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+	format := data.Format.ValueString()
+
+	if !utils.IsValidFormat(format) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+		return
+	}
+
+	rotated, err := utils.RotateDataKeyFile(filename, format, r.providerData.decryptOptions())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to rotate data key", fmt.Sprintf("failed to rotate data key of %q: %v", filename, err))
+		return
+	}
+
+	metadata, err := utils.ReadMetadata(rotated, format)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read sops metadata", fmt.Sprintf("failed to read sops metadata of %q: %v", filename, err))
+		return
+	}
+
+	data.ID = types.StringValue(filename)
+	data.LastModified = types.StringValue(metadata.LastModified.UTC().Format(time.RFC3339))
+
+	resp.Diagnostics.Append(data.setRotationRFC3339()...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *dataKeyRotationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data dataKeyRotationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, err := os.Stat(data.Filename.ValueString()); errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if data.RotationRFC3339.IsNull() {
+		return
+	}
+
+	rotation, err := time.Parse(time.RFC3339, data.RotationRFC3339.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rotation_rfc3339"),
+			"Invalid rotation timestamp",
+			fmt.Sprintf("failed to parse rotation timestamp %q: %v", data.RotationRFC3339.ValueString(), err),
+		)
+		return
+	}
+
+	// the rotation is due, so the resource has to be created again, which rotates the data key
+	if !time.Now().Before(rotation) {
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *dataKeyRotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data dataKeyRotationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// only the rotation window or the format changed, the data key is not rotated before the
+	// rotation is due
+	resp.Diagnostics.Append(data.setRotationRFC3339()...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *dataKeyRotationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// the file is not owned by this resource, so it is only removed from the state
+}
+
+// setRotationRFC3339 sets the time of the next rotation of the model from its last rotation and
+// its rotation window.
+func (m *dataKeyRotationResourceModel) setRotationRFC3339() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.RotationDays.IsNull() {
+		m.RotationRFC3339 = types.StringNull()
+		return diags
+	}
+
+	lastModified, err := time.Parse(time.RFC3339, m.LastModified.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("lastmodified"),
+			"Invalid lastmodified timestamp",
+			fmt.Sprintf("failed to parse lastmodified timestamp %q: %v", m.LastModified.ValueString(), err),
+		)
+		return diags
+	}
+
+	rotation := lastModified.AddDate(0, 0, int(m.RotationDays.ValueInt64()))
+	m.RotationRFC3339 = types.StringValue(rotation.Format(time.RFC3339))
+
+	return diags
+}
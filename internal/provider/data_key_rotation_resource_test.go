@@ -0,0 +1,99 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+func TestDataKeyRotationResource_basic(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret.sops.yaml")
+	provider := testHelperEncryptedFileProviderConfig(t)
+
+	opts := utils.EncryptOptions{KeyGroups: []utils.KeyGroup{{Age: []string{test_age_recipient}}}}
+	if _, err := utils.EncryptFile(file, []byte("password: hunter2\n"), "yaml", opts); err != nil {
+		t.Fatal(err)
+	}
+
+	config := func(rotationDays int, trigger string) string {
+		return provider + testHelperResourceConfig("sops_data_key_rotation", fmt.Sprintf(`
+	filename      = %q
+	rotation_days = %d
+	triggers = {
+		reason = %q
+	}
+`, file, rotationDays, trigger))
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(90, "initial"),
+				Check:  testCheckEncryptedFile(file, "yaml", "password: hunter2\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"sops_data_key_rotation.test",
+						tfjsonpath.New("format"),
+						knownvalue.StringExact("yaml"),
+					),
+					statecheck.ExpectKnownValue(
+						"sops_data_key_rotation.test",
+						tfjsonpath.New("lastmodified"),
+						knownvalue.StringRegexp(regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z$`)),
+					),
+					statecheck.ExpectKnownValue(
+						"sops_data_key_rotation.test",
+						tfjsonpath.New("rotation_rfc3339"),
+						knownvalue.NotNull(),
+					),
+				},
+			},
+			// a new rotation window does not rotate the data key before it is due
+			{
+				Config: config(30, "initial"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sops_data_key_rotation.test", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+			// changed triggers rotate the data key
+			{
+				Config: config(30, "incident"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sops_data_key_rotation.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: testCheckEncryptedFile(file, "yaml", "password: hunter2\n"),
+			},
+		},
+	})
+}
+
+func TestDataKeyRotationResource_invalid(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperResourceConfig("sops_data_key_rotation", `
+	filename      = "secret.sops.yaml"
+	rotation_days = 0
+`),
+				ExpectError: regexp.MustCompile("rotation_days must be at least 1"),
+			},
+		},
+	})
+}
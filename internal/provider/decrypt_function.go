@@ -0,0 +1,495 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+const (
+	// sourceTypePath indicates that the source is the path to a sops encrypted file.
+	sourceTypePath = "path"
+	// sourceTypeInline indicates that the source is the sops encrypted data itself.
+	sourceTypeInline = "inline"
+)
+
+var sopsDecryptReturnAttrTypes = map[string]attr.Type{
+	"raw":  types.StringType,
+	"data": types.DynamicType,
+}
+
+// sopsDecryptWithMACReturnAttrTypes extends sopsDecryptReturnAttrTypes with the integrity
+// information of the decrypted document.
+var sopsDecryptWithMACReturnAttrTypes = map[string]attr.Type{
+	"raw":                  types.StringType,
+	"data":                 types.DynamicType,
+	"mac_valid":            types.BoolType,
+	"expected_mac_present": types.BoolType,
+	"lastmodified":         types.StringType,
+}
+
+// decryptRequest describes a single decryption performed by one of the provider functions. All
+// decrypting functions share this request, so that new behaviour only has to be added once.
+type decryptRequest struct {
+	// source is either the path to a sops encrypted file or the encrypted data itself, depending
+	// on sourceType.
+	source string
+	// sourceType is either sourceTypePath or sourceTypeInline.
+	sourceType string
+	// format is the format of the encrypted data. If empty, it is inferred from the file extension
+	// for paths and defaults to binary for inline data.
+	format string
+	// outputFormat is the format of the decrypted data. If empty, format is used.
+	outputFormat string
+	// ignoreMAC indicates whether to ignore MAC mismatch errors.
+	ignoreMAC bool
+	// extract is the path of a single value to return instead of the whole document. If empty,
+	// the whole document is returned.
+	extract string
+	// reportMAC indicates whether the integrity information of the document is added to the
+	// result, see sopsDecryptWithMACReturnAttrTypes.
+	reportMAC bool
+	// keys contains the key material to decrypt the data key with. If nil, sops looks up the key
+	// material in the process environment.
+	keys *utils.Keys
+}
+
+// run performs the decryption and returns the decrypted data as an object with the raw data and,
+// for structured formats, the parsed data. If reportMAC is set, the object also contains the
+// integrity information of the document.
+func (r decryptRequest) run(ctx context.Context) (types.Object, *function.FuncError) {
+	format := r.format
+	if format == "" {
+		if r.sourceType == sourceTypePath {
+			// infer format from file extension if not explicitly provided
+			format = utils.FileFormatFromPath(r.source)
+		} else {
+			format = "binary"
+		}
+	}
+
+	if !utils.IsValidFormat(format) {
+		return types.Object{}, function.NewFuncError(fmt.Sprintf("invalid format: %s", format))
+	}
+
+	outputFormat := r.outputFormat
+	if outputFormat == "" {
+		outputFormat = format
+	}
+
+	if !utils.IsValidFormat(outputFormat) {
+		return types.Object{}, function.NewFuncError(fmt.Sprintf("invalid output format: %s", outputFormat))
+	}
+
+	opts := utils.DecryptOptions{
+		IgnoreMACMismatch: r.ignoreMAC,
+		OutputFormat:      outputFormat,
+		Keys:              r.keys,
+	}
+
+	var decrypted utils.DecryptResult
+	var err error
+
+	switch r.sourceType {
+	case sourceTypePath:
+		decrypted, err = utils.DecryptFileDetailed(r.source, format, opts)
+	case sourceTypeInline:
+		decrypted, err = utils.DecryptDataDetailed([]byte(r.source), format, opts)
+	default:
+		return types.Object{}, function.NewFuncError(fmt.Sprintf("invalid source type: %s", r.sourceType))
+	}
+
+	if err != nil {
+		return types.Object{}, function.NewFuncError(fmt.Sprintf("failed to decrypt file: %v", err))
+	}
+
+	json, err := utils.UnmarshalDecryptedData(decrypted.Cleartext, outputFormat)
+	if err != nil {
+		return types.Object{}, function.NewFuncError(fmt.Sprintf("failed to unmarshal decrypted data: %v", err))
+	}
+
+	raw := string(decrypted.Cleartext)
+
+	var dynamicData types.Dynamic
+	if r.extract != "" {
+		raw, dynamicData, err = extractValue(json, r.extract)
+		if err != nil {
+			return types.Object{}, function.NewFuncError(fmt.Sprintf("failed to extract %s: %v", r.extract, err))
+		}
+	} else {
+		dynamicData, err = utils.JSONToDynamicImplied(json)
+		if err != nil {
+			return types.Object{}, function.NewFuncError(fmt.Sprintf("failed to convert decrypted data to dynamic data: %v", err))
+		}
+	}
+
+	attrTypes := sopsDecryptReturnAttrTypes
+	attrValues := map[string]attr.Value{
+		"raw":  types.StringValue(raw),
+		"data": dynamicData,
+	}
+
+	if r.reportMAC {
+		attrTypes = sopsDecryptWithMACReturnAttrTypes
+		attrValues["mac_valid"] = types.BoolValue(decrypted.MACValid)
+		attrValues["expected_mac_present"] = types.BoolValue(decrypted.ExpectedMACPresent)
+		attrValues["lastmodified"] = types.StringValue(decrypted.LastModified.UTC().Format(time.RFC3339))
+	}
+
+	result, diags := types.ObjectValue(attrTypes, attrValues)
+
+	return result, function.FuncErrorFromDiags(ctx, diags)
+}
+
+// extractValue extracts the value at the given path from the decrypted data in JSON format. It
+// returns the value as raw string, which is the JSON encoding for objects and arrays, and as
+// dynamic data, which is a string for scalar values.
+func extractValue(json []byte, extractPath string) (string, types.Dynamic, error) {
+	subtree, raw, err := utils.Extract(json, extractPath)
+	if err != nil {
+		return "", types.Dynamic{}, err
+	}
+
+	if subtree == nil {
+		return raw, types.DynamicValue(types.StringValue(raw)), nil
+	}
+
+	dynamicData, err := utils.JSONToDynamicImplied(subtree)
+	if err != nil {
+		return "", types.Dynamic{}, fmt.Errorf("failed to convert extracted data to dynamic data: %w", err)
+	}
+
+	return string(subtree), dynamicData, nil
+}
+
+// runDecryptRequest runs the given request and sets the result on the response.
+func runDecryptRequest(ctx context.Context, req decryptRequest, resp *function.RunResponse) {
+	result, funcErr := req.run(ctx)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, &result)
+}
+
+// decryptResultModel is the object returned by decryptRequest.run without the integrity
+// information.
+type decryptResultModel struct {
+	Raw  types.String  `tfsdk:"raw"`
+	Data types.Dynamic `tfsdk:"data"`
+}
+
+// runWithDiagnostics runs the request for a data source, resource, or ephemeral resource. Errors
+// are added to the given diagnostics with the given summary instead of being returned as function
+// errors.
+func (r decryptRequest) runWithDiagnostics(ctx context.Context, summary string, diags *diag.Diagnostics) decryptResultModel {
+	var model decryptResultModel
+
+	result, funcErr := r.run(ctx)
+	if funcErr != nil {
+		diags.AddError(summary, funcErr.Text)
+		return model
+	}
+
+	diags.Append(result.As(ctx, &model, basetypes.ObjectAsOptions{})...)
+
+	return model
+}
+
+// Ensure that decryptFunction implements the Function interface.
+var _ function.Function = &decryptFunction{}
+
+type decryptFunction struct{}
+
+func NewDecryptFunction() function.Function {
+	return &decryptFunction{}
+}
+
+func (f *decryptFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "decrypt"
+}
+
+func (f *decryptFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Decrypts a sops encrypted file or string.",
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Decrypts a [sops](https://getsops.io/) encrypted file or string. The behaviour is controlled by
+			an options object, which supports the following attributes. All of them are optional.
+
+			- ` + utils.Code("source_type") + ` - Either ` + utils.Code("path") + ` if the source is the path
+			  to a sops encrypted file, or ` + utils.Code("inline") + ` if the source is the encrypted data
+			  itself. Defaults to ` + utils.Code("path") + `.
+			- ` + utils.Code("format") + ` - The format of the encrypted data. Supported formats are ` +
+			utils.Code("yaml") + `, ` + utils.Code("json") + `, ` + utils.Code("dotenv") + `, ` +
+			utils.Code("ini") + `, and ` + utils.Code("binary") + `. Inferred from the file extension for
+			  paths and ` + utils.Code("binary") + ` for inline data if not provided.
+			- ` + utils.Code("ignore_mac") + ` - Whether to return the decrypted data even if the MAC does not
+			  match, instead of failing. Defaults to ` + utils.Code("false") + `.
+			- ` + utils.Code("output_format") + ` - The format to convert the decrypted data to. Defaults to
+			  the format of the encrypted data.
+			- ` + utils.Code("extract") + ` - The path of a single value to return instead of the whole
+			  document, see the ` + utils.Code("extract") + ` function for the supported syntax.
+			- ` + utils.Code("keys") + ` - The key material to decrypt the data key with, see the ` +
+			utils.Code("file_with_keys") + ` function for the supported attributes. If not provided,
+			  the key material is looked up in the environment.
+
+			If the output format is any of the supported formats other than ` + utils.Code("binary") + `, the
+			decrypted data will also be returned as an object in the ` + utils.Code("data") + ` attribute.
+			Regardless of the format, the raw decrypted data will always be returned in the ` +
+			utils.Code("raw") + ` attribute.
+
+			The integrity information of the document is returned alongside the data: ` +
+			utils.Code("mac_valid") + ` indicates whether the MAC matches the decrypted data, ` +
+			utils.Code("expected_mac_present") + ` whether the document contains a MAC at all, and ` +
+			utils.Code("lastmodified") + ` when the document was last modified by sops. Together with ` +
+			utils.Code("ignore_mac") + `, this allows to handle a MAC mismatch gracefully, e.g. in a ` +
+			utils.Code("check") + ` block with a custom error message.
+
+			Decryption is based on the sops library, so unless ` + utils.Code("keys") + ` is provided, it
+			will use the same heuristics and key sources as sops to attempt to decrypt the data.
+		`)),
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "source",
+				MarkdownDescription: "The path to the sops encrypted file, or the sops encrypted data if `source_type` is `inline`.",
+			},
+			function.DynamicParameter{
+				Name:                "options",
+				MarkdownDescription: "An object with the decryption options. May be `null` or `{}` to use the defaults.",
+				AllowNullValue:      true,
+			},
+		},
+
+		Return: function.ObjectReturn{
+			AttributeTypes: sopsDecryptWithMACReturnAttrTypes,
+		},
+	}
+}
+
+func (f *decryptFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var source string
+	var options types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &source, &options)
+	if resp.Error != nil {
+		return
+	}
+
+	decryptReq := decryptRequest{
+		source:     source,
+		sourceType: sourceTypePath,
+		reportMAC:  true,
+	}
+
+	resp.Error = parseOptions(options, 1, decryptOptionParsers, &decryptReq)
+	if resp.Error != nil {
+		return
+	}
+
+	runDecryptRequest(ctx, decryptReq, resp)
+}
+
+// optionParser parses a single attribute of an options object and applies it to the request.
+type optionParser func(value attr.Value, req *decryptRequest) error
+
+// decryptOptionParsers contains a parser for every supported attribute of the decrypt options
+// object. New options only need to be added here.
+var decryptOptionParsers = map[string]optionParser{
+	"source_type": parseSourceTypeOption,
+	"format":      parseFormatOption,
+	"output_format": func(value attr.Value, req *decryptRequest) (err error) {
+		req.outputFormat, err = stringOption(value)
+		return err
+	},
+	"ignore_mac": func(value attr.Value, req *decryptRequest) (err error) {
+		req.ignoreMAC, err = boolOption(value)
+		return err
+	},
+	"extract": func(value attr.Value, req *decryptRequest) (err error) {
+		req.extract, err = stringOption(value)
+		return err
+	},
+	"keys": parseKeysOption,
+}
+
+// parseSourceTypeOption parses the source_type option.
+func parseSourceTypeOption(value attr.Value, req *decryptRequest) error {
+	sourceType, err := stringOption(value)
+	if err != nil {
+		return err
+	}
+
+	if sourceType != sourceTypePath && sourceType != sourceTypeInline {
+		return fmt.Errorf("must be either %q or %q, got %q", sourceTypePath, sourceTypeInline, sourceType)
+	}
+
+	req.sourceType = sourceType
+	return nil
+}
+
+// parseFormatOption parses the format option.
+func parseFormatOption(value attr.Value, req *decryptRequest) (err error) {
+	req.format, err = stringOption(value)
+	return err
+}
+
+// keysOptionAttributes contains the supported attributes of the keys option, see parseKeysOption.
+var keysOptionAttributes = map[string]func(material *utils.KeyMaterial, values []string){
+	"age":         func(material *utils.KeyMaterial, values []string) { material.Age = values },
+	"pgp_armored": func(material *utils.KeyMaterial, values []string) { material.PGPArmored = values },
+}
+
+// parseKeysOption parses the keys option, an object with lists of key material per key type.
+func parseKeysOption(value attr.Value, req *decryptRequest) error {
+	var attributes map[string]attr.Value
+	switch value := value.(type) {
+	case types.Object:
+		attributes = value.Attributes()
+	case types.Map:
+		attributes = value.Elements()
+	default:
+		return errors.New("must be an object")
+	}
+
+	// iterate in a stable order, so that errors are deterministic
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var material utils.KeyMaterial
+	for _, name := range names {
+		set, ok := keysOptionAttributes[name]
+		if !ok {
+			return fmt.Errorf("unsupported key type: %s", name)
+		}
+
+		value := attributes[name]
+		if value.IsNull() {
+			continue
+		}
+
+		values, err := stringListOption(value)
+		if err != nil {
+			return fmt.Errorf("%s %w", name, err)
+		}
+
+		set(&material, values)
+	}
+
+	keys, err := utils.NewKeysFromMaterial(material)
+	if err != nil {
+		return err
+	}
+
+	req.keys = keys
+	return nil
+}
+
+// parseOptions parses the options object passed as the argument at the given position using the
+// given parsers and applies it to the request. Null options and null attributes keep their
+// defaults.
+func parseOptions(options types.Dynamic, position int64, parsers map[string]optionParser, req *decryptRequest) *function.FuncError {
+	if options.IsNull() || options.IsUnderlyingValueNull() {
+		return nil
+	}
+
+	var attributes map[string]attr.Value
+	switch value := options.UnderlyingValue().(type) {
+	case types.Object:
+		attributes = value.Attributes()
+	case types.Map:
+		attributes = value.Elements()
+	default:
+		return function.NewArgumentFuncError(position, "options must be an object")
+	}
+
+	// iterate in a stable order, so that errors are deterministic
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		parse, ok := parsers[name]
+		if !ok {
+			return function.NewArgumentFuncError(position, fmt.Sprintf("unsupported option: %s", name))
+		}
+
+		value := attributes[name]
+		if value.IsNull() {
+			continue
+		}
+
+		if err := parse(value, req); err != nil {
+			return function.NewArgumentFuncError(position, fmt.Sprintf("invalid option %s: %v", name, err))
+		}
+	}
+
+	return nil
+}
+
+// stringOption returns the value of a string option.
+func stringOption(value attr.Value) (string, error) {
+	s, ok := value.(types.String)
+	if !ok {
+		return "", errors.New("must be a string")
+	}
+
+	return s.ValueString(), nil
+}
+
+// boolOption returns the value of a bool option.
+func boolOption(value attr.Value) (bool, error) {
+	b, ok := value.(types.Bool)
+	if !ok {
+		return false, errors.New("must be a bool")
+	}
+
+	return b.ValueBool(), nil
+}
+
+// stringListOption returns the value of a list of strings option.
+func stringListOption(value attr.Value) ([]string, error) {
+	var elements []attr.Value
+	switch value := value.(type) {
+	case types.List:
+		elements = value.Elements()
+	case types.Tuple:
+		elements = value.Elements()
+	case types.Set:
+		elements = value.Elements()
+	default:
+		return nil, errors.New("must be a list of strings")
+	}
+
+	values := make([]string, 0, len(elements))
+	for _, element := range elements {
+		s, ok := element.(types.String)
+		if !ok || s.IsNull() {
+			return nil, errors.New("must be a list of strings")
+		}
+
+		values = append(values, s.ValueString())
+	}
+
+	return values, nil
+}
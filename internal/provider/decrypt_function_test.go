@@ -0,0 +1,287 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestDecryptFunction_path(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), "null"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"data": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"abc":      knownvalue.StringExact("xyz"),
+								"integers": knownvalue.Int64Exact(123),
+								"truthy":   knownvalue.Bool(true),
+								"floats":   knownvalue.Float64Exact(3.14e-10),
+							}),
+						}),
+					),
+				},
+			},
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ source_type = "path", format = "yaml" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"data": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"abc":      knownvalue.StringExact("xyz"),
+								"integers": knownvalue.Int64Exact(123),
+								"truthy":   knownvalue.Bool(true),
+								"floats":   knownvalue.Float64Exact(3.14e-10),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestDecryptFunction_inline(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_raw_file)
+	jsonFixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_json_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("file(%q)", fixture), `{ source_type = "inline" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"raw":  knownvalue.StringExact("Lorem ipsum dolor sit amet, consectetur adipiscing elit.\n"),
+							"data": knownvalue.Null(),
+						}),
+					),
+				},
+			},
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("file(%q)", jsonFixture), `{ source_type = "inline", format = "json" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"data": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"abc":      knownvalue.StringExact("xyz"),
+								"integers": knownvalue.Int64Exact(123),
+								"truthy":   knownvalue.Bool(true),
+								"floats":   knownvalue.Float64Exact(3.14e-10),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestDecryptFunction_output_format(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ output_format = "dotenv" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"raw": knownvalue.StringExact("abc=xyz\nintegers=123\ntruthy=true\nfloats=3.14E-10\n"),
+							"data": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"abc":      knownvalue.StringExact("xyz"),
+								"integers": knownvalue.StringExact("123"),
+								"truthy":   knownvalue.StringExact("true"),
+								"floats":   knownvalue.StringExact("3.14E-10"),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestDecryptFunction_ignore_mac(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_mac_mismatch_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), "{}"),
+				ExpectError: regexp.MustCompile(
+					".*failed to verify data integrity.*",
+				),
+			},
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ ignore_mac = true }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"data": knownvalue.ObjectPartial(map[string]knownvalue.Check{
+								"abc": knownvalue.StringExact("xyz"),
+							}),
+							"mac_valid":            knownvalue.Bool(false),
+							"expected_mac_present": knownvalue.Bool(true),
+							"lastmodified":         knownvalue.StringExact("2024-11-27T20:58:06Z"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestDecryptFunction_mac_valid(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ ignore_mac = true }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"mac_valid":            knownvalue.Bool(true),
+							"expected_mac_present": knownvalue.Bool(true),
+							"lastmodified":         knownvalue.StringExact("2024-11-27T20:58:06Z"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestDecryptFunction_invalid_options(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ foo = "bar" }`),
+				ExpectError: regexp.MustCompile("unsupported option: foo"),
+			},
+			{
+				Config:      testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ source_type = "url" }`),
+				ExpectError: regexp.MustCompile("invalid option source_type"),
+			},
+			{
+				Config:      testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ format = "foobar" }`),
+				ExpectError: regexp.MustCompile("invalid format:.*"),
+			},
+			{
+				Config:      testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `"yaml"`),
+				ExpectError: regexp.MustCompile("options must be an object"),
+			},
+		},
+	})
+}
+
+func TestDecryptFunction_extract(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", fixture), `{ extract = "/object_key/nested_list" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"raw": knownvalue.StringExact(`["nested item1",200]`),
+							"data": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.StringExact("nested item1"),
+								knownvalue.Int64Exact(200),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
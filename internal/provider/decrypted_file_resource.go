@@ -0,0 +1,337 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// defaultFilePermission is the permission decrypted files are written with by default.
+const defaultFilePermission = "0600"
+
+// Ensure that decryptedFileResource implements the Resource interfaces.
+var _ resource.Resource = &decryptedFileResource{}
+var _ resource.ResourceWithConfigure = &decryptedFileResource{}
+var _ resource.ResourceWithModifyPlan = &decryptedFileResource{}
+var _ resource.ResourceWithValidateConfig = &decryptedFileResource{}
+
+type decryptedFileResource struct {
+	providerData *sopsProviderData
+}
+
+type decryptedFileResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Source         types.String `tfsdk:"source"`
+	SourceFormat   types.String `tfsdk:"source_format"`
+	Filename       types.String `tfsdk:"filename"`
+	OutputFormat   types.String `tfsdk:"output_format"`
+	FilePermission types.String `tfsdk:"file_permission"`
+	SourceSHA256   types.String `tfsdk:"source_sha256"`
+	ContentSHA256  types.String `tfsdk:"content_sha256"`
+}
+
+func NewDecryptedFileResource() resource.Resource {
+	return &decryptedFileResource{}
+}
+
+func (r *decryptedFileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_decrypted_file"
+}
+
+func (r *decryptedFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Decrypts a [sops](https://getsops.io/) encrypted file into a plaintext file, e.g. for tools that
+			can only read plaintext files from disk. The decrypted data can optionally be converted to a
+			different format using ` + utils.Code("output_format") + `. The file is written with
+			` + utils.Code("0600") + ` permissions unless ` + utils.Code("file_permission") + ` is set, and
+			deleted again when the resource is destroyed.
+
+			Neither the plaintext nor the ciphertext are stored in the state, only their checksums. The
+			file is written again if the source file changes, or if the file was modified or deleted
+			outside of Terraform. Changed permissions are restored.
+
+			If the plaintext file is only needed during a single run, prefer the
+			` + utils.Code("sops_temp_file") + ` ephemeral resource, which never leaves the file behind.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The path of the decrypted file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops encrypted source file.",
+				Required:            true,
+			},
+			"source_format": schema.StringAttribute{
+				MarkdownDescription: "The format of the source file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"filename": schema.StringAttribute{
+				MarkdownDescription: "The path of the decrypted file to write. Missing parent directories are created. Changing the path replaces the file.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"output_format": schema.StringAttribute{
+				MarkdownDescription: "The format the decrypted data is written in. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. Defaults to `source_format`.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"file_permission": schema.StringAttribute{
+				MarkdownDescription: "The permissions of the decrypted file as octal string, e.g. `0640`. Defaults to `0600`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultFilePermission),
+			},
+			"source_sha256": schema.StringAttribute{
+				MarkdownDescription: "The SHA256 checksum of the source file the file was written from, as hex string.",
+				Computed:            true,
+			},
+			"content_sha256": schema.StringAttribute{
+				MarkdownDescription: "The SHA256 checksum of the decrypted file, as hex string.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *decryptedFileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *decryptedFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data decryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, attribute := range []string{"source_format", "output_format"} {
+		var format types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &format)...)
+
+		if format.ValueString() != "" && !utils.IsValidFormat(format.ValueString()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute),
+				"Invalid format",
+				fmt.Sprintf("invalid format: %s", format.ValueString()),
+			)
+		}
+	}
+
+	if permission := data.FilePermission.ValueString(); permission != "" {
+		if _, err := parseFilePermission(permission); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("file_permission"),
+				"Invalid file permission",
+				err.Error(),
+			)
+		}
+	}
+}
+
+func (r *decryptedFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan decryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// infer formats from the file extension if not explicitly provided, so that they are known
+	// during plan
+	if plan.SourceFormat.IsUnknown() && !plan.Source.IsUnknown() {
+		plan.SourceFormat = types.StringValue(utils.FileFormatFromPath(plan.Source.ValueString()))
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("source_format"), plan.SourceFormat)...)
+	}
+
+	// keep the source format if no output format is provided
+	if plan.OutputFormat.IsUnknown() && !plan.SourceFormat.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("output_format"), plan.SourceFormat)...)
+	}
+
+	// nothing else to do on create
+	if req.State.Raw.IsNull() || plan.Source.IsUnknown() {
+		return
+	}
+
+	// the file has to be written again if the source file changed since it was written
+	source, err := os.ReadFile(plan.Source.ValueString())
+	if err != nil || sha256Hex(source) == plan.SourceSHA256.ValueString() {
+		return
+	}
+
+	for _, attribute := range []string{"source_sha256", "content_sha256"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attribute), types.StringUnknown())...)
+	}
+}
+
+func (r *decryptedFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data decryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.decrypt(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *decryptedFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data decryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+
+	info, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", fmt.Sprintf("failed to read %q: %v", filename, err))
+		return
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", fmt.Sprintf("failed to read %q: %v", filename, err))
+		return
+	}
+
+	// the file was modified outside of Terraform, so it has to be written again
+	if sha256Hex(content) != data.ContentSHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// record changed permissions, so that they are restored
+	if perm, err := parseFilePermission(data.FilePermission.ValueString()); err != nil || perm != info.Mode().Perm() {
+		data.FilePermission = types.StringValue(formatFilePermission(info.Mode().Perm()))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *decryptedFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data decryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.decrypt(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *decryptedFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data decryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(data.Filename.ValueString()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError("Failed to delete file", fmt.Sprintf("failed to delete %q: %v", data.Filename.ValueString(), err))
+	}
+}
+
+// decrypt decrypts the source file of the given model and writes the plaintext to its file,
+// updating the computed attributes of the model.
+func (r *decryptedFileResource) decrypt(data *decryptedFileResourceModel, diags *diag.Diagnostics) {
+	sourcePath := data.Source.ValueString()
+	filename := data.Filename.ValueString()
+
+	perm, err := parseFilePermission(data.FilePermission.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("file_permission"), "Invalid file permission", err.Error())
+		return
+	}
+
+	source, err := os.ReadFile(sourcePath)
+	if err != nil {
+		diags.AddAttributeError(path.Root("source"), "Failed to read source file", fmt.Sprintf("failed to read %q: %v", sourcePath, err))
+		return
+	}
+
+	opts := r.providerData.decryptOptions()
+	opts.OutputFormat = data.OutputFormat.ValueString()
+
+	cleartext, err := utils.DecryptData(source, data.SourceFormat.ValueString(), opts)
+	if err != nil {
+		diags.AddError("Failed to decrypt source file", fmt.Sprintf("failed to decrypt %q: %v", sourcePath, err))
+		return
+	}
+
+	// the file is written to a temporary file with 0600 permissions first, so the plaintext is
+	// never readable with broader permissions than requested
+	if err := utils.WriteFileAtomic(filename, cleartext, perm); err != nil {
+		diags.AddError("Failed to write file", fmt.Sprintf("failed to write %q: %v", filename, err))
+		return
+	}
+
+	data.ID = types.StringValue(filename)
+	data.SourceSHA256 = types.StringValue(sha256Hex(source))
+	data.ContentSHA256 = types.StringValue(sha256Hex(cleartext))
+}
+
+// parseFilePermission parses the given octal file permission, e.g. "0640".
+func parseFilePermission(permission string) (os.FileMode, error) {
+	perm, err := strconv.ParseUint(permission, 8, 32)
+	if err != nil || perm > 0o777 {
+		return 0, fmt.Errorf("invalid file permission %q: must be an octal string between 0000 and 0777, e.g. 0640", permission)
+	}
+
+	return os.FileMode(perm), nil
+}
+
+// formatFilePermission formats the given file permission as octal string, e.g. "0640".
+func formatFilePermission(perm os.FileMode) string {
+	return fmt.Sprintf("%04o", uint32(perm))
+}
@@ -0,0 +1,161 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// testCheckDecryptedFile checks that the file at the given path has the given content and
+// permissions.
+func testCheckDecryptedFile(path string, content string, perm os.FileMode) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().Perm() != perm {
+			return fmt.Errorf("expected permissions of %q to be %v, got %v", path, perm, info.Mode().Perm())
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if string(data) != content {
+			return fmt.Errorf("expected content of %q to be %q, got %q", path, content, data)
+		}
+
+		return nil
+	}
+}
+
+// testCheckFileNotExists checks that the file at the given path does not exist.
+func testCheckFileNotExists(path string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			return fmt.Errorf("expected %q to not exist, got %v", path, err)
+		}
+
+		return nil
+	}
+}
+
+func TestDecryptedFileResource_basic(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	filename := filepath.Join(t.TempDir(), "config", "basic.json")
+
+	const content = "{\n\t\"abc\": \"xyz\",\n\t\"integers\": 123,\n\t\"truthy\": true,\n\t\"floats\": 3.14e-10\n}\n"
+
+	config := testHelperEncryptedFileProviderConfig(t) + fmt.Sprintf(`
+resource "sops_decrypted_file" "test" {
+	source          = %q
+	filename        = %q
+	output_format   = "json"
+	file_permission = "0640"
+}
+`, source, filename)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testCheckFileNotExists(filename),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("sops_decrypted_file.test", "source_format", "yaml"),
+					resource.TestCheckResourceAttr("sops_decrypted_file.test", "content_sha256", sha256Hex([]byte(content))),
+					testCheckDecryptedFile(filename, content, 0o640),
+				),
+			},
+			// changed permissions are restored
+			{
+				PreConfig: func() {
+					if err := os.Chmod(filename, 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				Check:  testCheckDecryptedFile(filename, content, 0o640),
+			},
+			// a modified file is written again
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(filename, []byte("tampered"), 0o640); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				Check:  testCheckDecryptedFile(filename, content, 0o640),
+			},
+		},
+	})
+}
+
+func TestDecryptedFileResource_defaults(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	filename := filepath.Join(t.TempDir(), "basic.yaml")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEncryptedFileProviderConfig(t) + fmt.Sprintf(`
+resource "sops_decrypted_file" "test" {
+	source   = %q
+	filename = %q
+}
+`, source, filename),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("sops_decrypted_file.test", "output_format", "yaml"),
+					resource.TestCheckResourceAttr("sops_decrypted_file.test", "file_permission", "0600"),
+					testCheckDecryptedFile(filename, "abc: xyz\nintegers: 123\ntruthy: true\nfloats: 3.14e-10\n", 0o600),
+				),
+			},
+		},
+	})
+}
+
+func TestDecryptedFileResource_invalid(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperResourceConfig("sops_decrypted_file", `
+	source          = "secrets.sops.yaml"
+	filename        = "secrets.yaml"
+	file_permission = "0999"
+`),
+				ExpectError: regexp.MustCompile("invalid file permission"),
+			},
+			{
+				Config: testHelperResourceConfig("sops_decrypted_file", `
+	source        = "secrets.sops.yaml"
+	filename      = "secrets.txt"
+	output_format = "txt"
+`),
+				ExpectError: regexp.MustCompile("invalid format: txt"),
+			},
+		},
+	})
+}
@@ -0,0 +1,516 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that encryptedFileResource implements the Resource interfaces.
+var _ resource.Resource = &encryptedFileResource{}
+var _ resource.ResourceWithConfigure = &encryptedFileResource{}
+var _ resource.ResourceWithModifyPlan = &encryptedFileResource{}
+var _ resource.ResourceWithValidateConfig = &encryptedFileResource{}
+
+type encryptedFileResource struct {
+	providerData *sopsProviderData
+}
+
+type encryptedFileResourceModel struct {
+	ID              types.String  `tfsdk:"id"`
+	Filename        types.String  `tfsdk:"filename"`
+	Format          types.String  `tfsdk:"format"`
+	Content         types.String  `tfsdk:"content"`
+	ContentObject   types.Dynamic `tfsdk:"content_object"`
+	Age             types.List    `tfsdk:"age"`
+	PGP             types.List    `tfsdk:"pgp"`
+	KMS             types.List    `tfsdk:"kms"`
+	KeyGroups       types.List    `tfsdk:"key_groups"`
+	ShamirThreshold types.Int64   `tfsdk:"shamir_threshold"`
+	EncryptedSHA256 types.String  `tfsdk:"encrypted_sha256"`
+}
+
+type keyGroupModel struct {
+	Age types.List `tfsdk:"age"`
+	PGP types.List `tfsdk:"pgp"`
+	KMS types.List `tfsdk:"kms"`
+}
+
+func NewEncryptedFileResource() resource.Resource {
+	return &encryptedFileResource{}
+}
+
+func (r *encryptedFileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_encrypted_file"
+}
+
+// recipientAttributes returns the schema attributes for the recipients of a key group. The given
+// note is appended to the description of every attribute.
+func recipientAttributes(note string) map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"age": schema.ListAttribute{
+			MarkdownDescription: strings.TrimSpace("The age recipients, e.g. `age1...`. " + note),
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+		"pgp": schema.ListAttribute{
+			MarkdownDescription: strings.TrimSpace("The fingerprints of the PGP keys. " + note),
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+		"kms": schema.ListAttribute{
+			MarkdownDescription: strings.TrimSpace("The ARNs of the AWS KMS keys. " + note),
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+	}
+}
+
+func (r *encryptedFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			MarkdownDescription: "The path of the encrypted file.",
+			Computed:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"filename": schema.StringAttribute{
+			MarkdownDescription: "The path of the encrypted file to write. Missing parent directories are created. Changing the path replaces the file.",
+			Required:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"format": schema.StringAttribute{
+			MarkdownDescription: "The format of the file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+			Optional:            true,
+			Computed:            true,
+		},
+		"content": schema.StringAttribute{
+			MarkdownDescription: "The plaintext content to encrypt, in the format of the file. Exactly one of `content` and `content_object` must be set.",
+			Optional:            true,
+			Sensitive:           true,
+		},
+		"content_object": schema.DynamicAttribute{
+			MarkdownDescription: "The plaintext content to encrypt as an object, which is converted into the format of the file. Not supported for `binary`. Exactly one of `content` and `content_object` must be set.",
+			Optional:            true,
+			Sensitive:           true,
+		},
+		"key_groups": schema.ListNestedAttribute{
+			MarkdownDescription: "The key groups to encrypt the file for. With more than one key group, the data key is split between them and `shamir_threshold` of them are required to decrypt the file. Conflicts with `age`, `pgp`, and `kms`.",
+			Optional:            true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: recipientAttributes(""),
+			},
+		},
+		"shamir_threshold": schema.Int64Attribute{
+			MarkdownDescription: "The number of key groups required to decrypt the file. Defaults to all key groups.",
+			Optional:            true,
+		},
+		"encrypted_sha256": schema.StringAttribute{
+			MarkdownDescription: "The SHA256 checksum of the encrypted file, as hex string.",
+			Computed:            true,
+		},
+	}
+
+	// the recipients of a single key group can also be set directly on the resource
+	maps.Copy(attributes, recipientAttributes("Used as a single key group. Conflicts with `key_groups`."))
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Writes a [sops](https://getsops.io/) encrypted file, e.g. to commit secrets generated by
+			Terraform to a git repository. The content is encrypted for the given age recipients, PGP
+			fingerprints, and AWS KMS keys, optionally split into key groups with a Shamir threshold.
+			Encrypting only requires the public keys of the recipients.
+
+			The file is only re-encrypted if its plaintext or its recipients change, as every encryption
+			produces a different ciphertext. Changes to the file made outside of Terraform are detected
+			by decrypting it and comparing its plaintext, which requires that the provider can decrypt
+			the file, e.g. with the identities configured on the provider. If it cannot, a warning is
+			emitted instead.
+
+			~> **Note:** The plaintext content is stored in the Terraform state, like any other argument.
+			Protect access to Terraform state accordingly.
+		`)),
+
+		Attributes: attributes,
+	}
+}
+
+func (r *encryptedFileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *encryptedFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data encryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Content.IsUnknown() && !data.ContentObject.IsUnknown() && data.Content.IsNull() == data.ContentObject.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("content"),
+			"Invalid content",
+			"exactly one of content and content_object must be set",
+		)
+	}
+
+	if !data.KeyGroups.IsNull() && (!data.Age.IsNull() || !data.PGP.IsNull() || !data.KMS.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("key_groups"),
+			"Conflicting recipients",
+			"key_groups cannot be combined with age, pgp, or kms",
+		)
+	}
+
+	if format := data.Format.ValueString(); format != "" && !utils.IsValidFormat(format) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+	}
+}
+
+func (r *encryptedFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan encryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// infer format from file extension if not explicitly provided, so that it is known during plan
+	if plan.Format.IsUnknown() && !plan.Filename.IsUnknown() {
+		format := utils.FileFormatFromPath(plan.Filename.ValueString())
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("format"), types.StringValue(format))...)
+	}
+}
+
+func (r *encryptedFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data encryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.encrypt(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *encryptedFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data encryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+
+	encrypted, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", fmt.Sprintf("failed to read %q: %v", filename, err))
+		return
+	}
+
+	// the file is unchanged since it was written, no need to decrypt it
+	checksum := sha256Hex(encrypted)
+	if checksum == data.EncryptedSHA256.ValueString() {
+		return
+	}
+
+	format := data.Format.ValueString()
+
+	cleartext, err := utils.DecryptData(encrypted, format, r.providerData.decryptOptions())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Unable to detect changes of encrypted file",
+			fmt.Sprintf("%q was modified outside of Terraform, but it cannot be decrypted to compare its plaintext: %v", filename, err),
+		)
+		return
+	}
+
+	equal, err := data.plaintextEqual(ctx, cleartext)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to compare plaintext", fmt.Sprintf("failed to compare plaintext of %q: %v", filename, err))
+		return
+	}
+
+	// the plaintext was changed outside of Terraform, record it so that the change shows up in the
+	// plan and the file is encrypted again
+	if !equal {
+		if !data.ContentObject.IsNull() {
+			json, err := utils.UnmarshalDecryptedData(cleartext, format)
+			if err != nil {
+				resp.Diagnostics.AddError("Failed to unmarshal decrypted data", fmt.Sprintf("failed to unmarshal decrypted data: %v", err))
+				return
+			}
+
+			data.ContentObject, err = utils.JSONToDynamicImplied(json)
+			if err != nil {
+				resp.Diagnostics.AddError("Failed to convert decrypted data", fmt.Sprintf("failed to convert decrypted data to dynamic data: %v", err))
+				return
+			}
+		} else {
+			data.Content = types.StringValue(string(cleartext))
+		}
+	}
+
+	data.EncryptedSHA256 = types.StringValue(checksum)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *encryptedFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state encryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unchanged, diags := data.unchangedFrom(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// every encryption produces a different ciphertext, so keep the file as long as neither the
+	// plaintext nor the recipients changed, e.g. if only the formatting of the content changed
+	if unchanged {
+		data.EncryptedSHA256 = state.EncryptedSHA256
+	} else {
+		r.encrypt(ctx, &data, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	data.ID = state.ID
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *encryptedFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data encryptedFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(data.Filename.ValueString()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError("Failed to delete file", fmt.Sprintf("failed to delete %q: %v", data.Filename.ValueString(), err))
+	}
+}
+
+// encrypt encrypts the content of the given model and writes it to its file, updating the
+// computed attributes of the model.
+func (r *encryptedFileResource) encrypt(ctx context.Context, data *encryptedFileResourceModel, diags *diag.Diagnostics) {
+	filename := data.Filename.ValueString()
+	format := data.Format.ValueString()
+
+	cleartext, inputFormat, err := data.cleartext(ctx)
+	if err != nil {
+		diags.AddError("Invalid content", err.Error())
+		return
+	}
+
+	opts, d := data.encryptOptions(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	opts.InputFormat = inputFormat
+
+	encrypted, err := utils.EncryptFile(filename, cleartext, format, opts)
+	if err != nil {
+		diags.AddError("Failed to encrypt file", fmt.Sprintf("failed to encrypt %q: %v", filename, err))
+		return
+	}
+
+	data.ID = types.StringValue(filename)
+	data.EncryptedSHA256 = types.StringValue(sha256Hex(encrypted))
+}
+
+// cleartext returns the plaintext content of the model and the format it is in. The format is
+// empty if the content is already in the format of the file.
+func (m encryptedFileResourceModel) cleartext(ctx context.Context) ([]byte, string, error) {
+	if m.ContentObject.IsNull() {
+		return []byte(m.Content.ValueString()), "", nil
+	}
+
+	if m.Format.ValueString() == "binary" {
+		return nil, "", errors.New("content_object is not supported for the binary format, use content instead")
+	}
+
+	json, err := utils.DynamicToJSON(ctx, m.ContentObject)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert content_object to JSON: %w", err)
+	}
+
+	return json, "json", nil
+}
+
+// plaintextEqual reports whether the plaintext content of the model equals the given decrypted
+// cleartext, ignoring differences in formatting.
+func (m encryptedFileResourceModel) plaintextEqual(ctx context.Context, decrypted []byte) (bool, error) {
+	format := m.Format.ValueString()
+
+	cleartext, inputFormat, err := m.cleartext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	want, err := utils.NormalizeCleartext(cleartext, inputFormat, format)
+	if err != nil {
+		return false, err
+	}
+
+	got, err := utils.NormalizeCleartext(decrypted, "", format)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(want, got), nil
+}
+
+// encryptOptions returns the encrypt options for the recipients of the model.
+func (m encryptedFileResourceModel) encryptOptions(ctx context.Context) (utils.EncryptOptions, diag.Diagnostics) {
+	return recipientsEncryptOptions(ctx, keyGroupModel{Age: m.Age, PGP: m.PGP, KMS: m.KMS}, m.KeyGroups, m.ShamirThreshold)
+}
+
+// recipientsEncryptOptions returns the encrypt options for the given key groups, or for the given
+// single key group if keyGroups is null.
+func recipientsEncryptOptions(ctx context.Context, single keyGroupModel, keyGroups types.List, shamirThreshold types.Int64) (utils.EncryptOptions, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	opts := utils.EncryptOptions{
+		ShamirThreshold: int(shamirThreshold.ValueInt64()),
+	}
+
+	groups := []keyGroupModel{single}
+	if !keyGroups.IsNull() {
+		groups = nil
+		diags.Append(keyGroups.ElementsAs(ctx, &groups, false)...)
+	}
+
+	for _, group := range groups {
+		var keyGroup utils.KeyGroup
+
+		diags.Append(group.Age.ElementsAs(ctx, &keyGroup.Age, false)...)
+		diags.Append(group.PGP.ElementsAs(ctx, &keyGroup.PGP, false)...)
+		diags.Append(group.KMS.ElementsAs(ctx, &keyGroup.KMS, false)...)
+
+		opts.KeyGroups = append(opts.KeyGroups, keyGroup)
+	}
+
+	return opts, diags
+}
+
+// unchangedFrom reports whether the encrypted file of the model would have the same plaintext and
+// recipients as the one of the given previous model, so that it does not need to be encrypted
+// again.
+func (m encryptedFileResourceModel) unchangedFrom(ctx context.Context, previous encryptedFileResourceModel) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !m.Format.Equal(previous.Format) {
+		return false, diags
+	}
+
+	opts, d := m.encryptOptions(ctx)
+	diags.Append(d...)
+
+	previousOpts, d := previous.encryptOptions(ctx)
+	diags.Append(d...)
+
+	if diags.HasError() || !sameRecipients(opts, previousOpts) {
+		return false, diags
+	}
+
+	previousCleartext, previousInputFormat, err := previous.cleartext(ctx)
+	if err != nil {
+		return false, diags
+	}
+
+	previousNormalized, err := utils.NormalizeCleartext(previousCleartext, previousInputFormat, previous.Format.ValueString())
+	if err != nil {
+		return false, diags
+	}
+
+	equal, err := m.plaintextEqual(ctx, previousNormalized)
+	if err != nil {
+		return false, diags
+	}
+
+	return equal, diags
+}
+
+// sameRecipients reports whether both encrypt options encrypt for the same key groups, regardless
+// of the order of the keys within a key group.
+func sameRecipients(a, b utils.EncryptOptions) bool {
+	if a.ShamirThreshold != b.ShamirThreshold || len(a.KeyGroups) != len(b.KeyGroups) {
+		return false
+	}
+
+	sorted := func(s []string) []string {
+		return slices.Sorted(slices.Values(s))
+	}
+
+	for i := range a.KeyGroups {
+		x, y := a.KeyGroups[i], b.KeyGroups[i]
+		if !slices.Equal(sorted(x.Age), sorted(y.Age)) ||
+			!slices.Equal(sorted(x.PGP), sorted(y.PGP)) ||
+			!slices.Equal(sorted(x.KMS), sorted(y.KMS)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sha256Hex returns the SHA256 checksum of the given data as hex string.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
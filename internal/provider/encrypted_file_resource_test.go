@@ -0,0 +1,266 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/compare"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+const test_age_recipient = "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn"
+
+// testCheckEncryptedFile checks that the file at the given path is sops encrypted and decrypts to
+// the expected cleartext with the test age key.
+func testCheckEncryptedFile(path string, format string, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		identity, err := os.ReadFile(fmt.Sprintf("%s/../../%s", wd, test_age_key_file))
+		if err != nil {
+			return err
+		}
+
+		keys, err := utils.NewKeys(string(identity))
+		if err != nil {
+			return err
+		}
+
+		cleartext, err := utils.DecryptFile(path, format, utils.DecryptOptions{Keys: keys})
+		if err != nil {
+			return err
+		}
+
+		if string(cleartext) != want {
+			return fmt.Errorf("expected %q to decrypt to %q, got %q", path, want, cleartext)
+		}
+
+		return nil
+	}
+}
+
+func testHelperEncryptedFileProviderConfig(t *testing.T) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fmt.Sprintf(`
+provider "sops" {
+	age_identity_file = %q
+}
+`, fmt.Sprintf("%s/../../%s", wd, test_age_key_file))
+}
+
+func TestEncryptedFileResource_yaml(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets", "secret.sops.yaml")
+	provider := testHelperEncryptedFileProviderConfig(t)
+
+	sameChecksum := statecheck.CompareValue(compare.ValuesSame())
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename = %q
+	content  = "password: hunter2\n"
+	age      = [%q]
+`, file, test_age_recipient)),
+				Check: testCheckEncryptedFile(file, "yaml", "password: hunter2\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"sops_encrypted_file.test",
+						tfjsonpath.New("format"),
+						knownvalue.StringExact("yaml"),
+					),
+					statecheck.ExpectKnownValue(
+						"sops_encrypted_file.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(file),
+					),
+					sameChecksum.AddStateValue(
+						"sops_encrypted_file.test",
+						tfjsonpath.New("encrypted_sha256"),
+					),
+				},
+			},
+			// only the formatting of the content changes, so the file is not encrypted again
+			{
+				Config: provider + testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename = %q
+	content  = "password:   hunter2\n"
+	age      = [%q]
+`, file, test_age_recipient)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameChecksum.AddStateValue(
+						"sops_encrypted_file.test",
+						tfjsonpath.New("encrypted_sha256"),
+					),
+				},
+			},
+			// the plaintext changes, so the file is encrypted again
+			{
+				Config: provider + testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename = %q
+	content  = "password: correct horse battery staple\n"
+	age      = [%q]
+`, file, test_age_recipient)),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sops_encrypted_file.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testCheckEncryptedFile(file, "yaml", "password: correct horse battery staple\n"),
+			},
+		},
+	})
+}
+
+func TestEncryptedFileResource_drift(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret.sops.yaml")
+	config := testHelperEncryptedFileProviderConfig(t) + testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename = %q
+	content  = "password: hunter2\n"
+	age      = [%q]
+`, file, test_age_recipient))
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			// encrypting the same plaintext again produces a different ciphertext, which is no drift
+			{
+				PreConfig: func() {
+					_, err := utils.EncryptFile(file, []byte("password: hunter2\n"), "yaml", utils.EncryptOptions{
+						KeyGroups: []utils.KeyGroup{{Age: []string{test_age_recipient}}},
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			// changing the plaintext outside of Terraform is detected and reverted
+			{
+				PreConfig: func() {
+					_, err := utils.EncryptFile(file, []byte("password: changed\n"), "yaml", utils.EncryptOptions{
+						KeyGroups: []utils.KeyGroup{{Age: []string{test_age_recipient}}},
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sops_encrypted_file.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testCheckEncryptedFile(file, "yaml", "password: hunter2\n"),
+			},
+			// deleting the file outside of Terraform creates it again
+			{
+				PreConfig: func() {
+					if err := os.Remove(file); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sops_encrypted_file.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testCheckEncryptedFile(file, "yaml", "password: hunter2\n"),
+			},
+		},
+	})
+}
+
+func TestEncryptedFileResource_content_object(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret.sops.json")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEncryptedFileProviderConfig(t) + testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename       = %q
+	content_object = {
+		password = "hunter2"
+		port     = 5432
+		hosts    = ["a", "b"]
+	}
+	key_groups = [
+		{ age = [%q] },
+	]
+`, file, test_age_recipient)),
+				Check: testCheckEncryptedFile(file, "json", "{\n\t\"hosts\": [\n\t\t\"a\",\n\t\t\"b\"\n\t],\n\t\"password\": \"hunter2\",\n\t\"port\": 5432\n}\n"),
+			},
+		},
+	})
+}
+
+func TestEncryptedFileResource_invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret.sops.yaml")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename = %q
+	age      = [%q]
+`, file, test_age_recipient)),
+				ExpectError: regexp.MustCompile("exactly one of content and content_object must be set"),
+			},
+			{
+				Config: testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename   = %q
+	content    = "a: b"
+	age        = [%q]
+	key_groups = [{ age = [%q] }]
+`, file, test_age_recipient, test_age_recipient)),
+				ExpectError: regexp.MustCompile("key_groups cannot be combined with age, pgp, or kms"),
+			},
+			{
+				Config: testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename = %q
+	content  = "a: b"
+`, file)),
+				ExpectError: regexp.MustCompile("at least one key is required to encrypt data"),
+			},
+			{
+				Config: testHelperResourceConfig("sops_encrypted_file", fmt.Sprintf(`
+	filename = %q
+	content  = "a: b"
+	age      = ["age1invalid"]
+`, file)),
+				ExpectError: regexp.MustCompile(`invalid age recipient "age1invalid"`),
+			},
+		},
+	})
+}
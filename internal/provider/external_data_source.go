@@ -0,0 +1,132 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that externalDataSource implements the DataSource interfaces.
+var _ datasource.DataSource = &externalDataSource{}
+var _ datasource.DataSourceWithConfigure = &externalDataSource{}
+
+type externalDataSource struct {
+	providerData *sopsProviderData
+}
+
+type externalDataSourceModel struct {
+	Source    types.String `tfsdk:"source"`
+	InputType types.String `tfsdk:"input_type"`
+	Extract   types.String `tfsdk:"extract"`
+	ID        types.String `tfsdk:"id"`
+	Data      types.Map    `tfsdk:"data"`
+	Raw       types.String `tfsdk:"raw"`
+}
+
+func NewExternalDataSource() datasource.DataSource {
+	return &externalDataSource{}
+}
+
+func (d *externalDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_external"
+}
+
+func (d *externalDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Decrypts a [sops](https://getsops.io/) encrypted string, e.g. fetched by another data source.
+			This data source is compatible with the ` + utils.Code("sops_external") + ` data source of
+			[carlpett/terraform-provider-sops](https://github.com/carlpett/terraform-provider-sops), so
+			existing configurations can be migrated by only changing the provider source.
+
+			The decrypted data is returned as a flat map in the ` + utils.Code("data") + ` attribute, with
+			nested keys joined by dots and list elements addressed by their index, e.g. ` +
+			utils.Code(`data["database.hosts.0"]`) + `. The raw decrypted data is returned in the ` +
+			utils.Code("raw") + ` attribute.
+
+			~> **Note:** The decrypted data is stored in the Terraform state. Prefer the ` +
+			utils.Code("sops_string") + ` ephemeral resource or the provider functions for new configurations.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"source": schema.StringAttribute{
+				MarkdownDescription: "The sops encrypted string.",
+				Required:            true,
+			},
+			"input_type": schema.StringAttribute{
+				MarkdownDescription: "The format of the encrypted string. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `raw`.",
+				Required:            true,
+			},
+			"extract": schema.StringAttribute{
+				MarkdownDescription: "The path of a single value to return instead of the whole document, either in the sops syntax, e.g. `[\"a\"][0]`, or as a JSON Pointer, e.g. `/a/0`. If set, `raw` and `data` only contain the extracted value, `data` is empty for scalar values.",
+				Optional:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Always `-`, for compatibility.",
+				Computed:            true,
+			},
+			"data": schema.MapAttribute{
+				MarkdownDescription: "The decrypted data as a flat map of strings. Empty for `raw`.",
+				ElementType:         types.StringType,
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"raw": schema.StringAttribute{
+				MarkdownDescription: "The raw decrypted data.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (d *externalDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *externalDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data externalDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	inputType := data.InputType.ValueString()
+
+	format, ok := formatFromInputType(inputType)
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("input_type"),
+			"Invalid input type",
+			fmt.Sprintf("invalid input type: %s", inputType),
+		)
+		return
+	}
+
+	decryptReq := decryptRequest{
+		source:     data.Source.ValueString(),
+		sourceType: sourceTypeInline,
+		format:     format,
+		extract:    data.Extract.ValueString(),
+		keys:       d.providerData.decryptOptions().Keys,
+	}
+
+	data.ID = types.StringValue("-")
+	data.Raw, data.Data = decryptFlattened(ctx, decryptReq, "Failed to decrypt data", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
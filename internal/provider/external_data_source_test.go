@@ -0,0 +1,142 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestExternalDataSource_sample_ini(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_sample_ini_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_external", fmt.Sprintf(`
+	source     = file(%q)
+	input_type = "ini"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sops_external.test",
+						tfjsonpath.New("data"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"general.appname":   knownvalue.StringExact("SampleApp"),
+							"general.version":   knownvalue.StringExact("1.0"),
+							"database.host":     knownvalue.StringExact("localhost"),
+							"database.port":     knownvalue.StringExact("3306"),
+							"database.username": knownvalue.StringExact("root"),
+							"database.password": knownvalue.StringExact("password"),
+							"logging.level":     knownvalue.StringExact("DEBUG"),
+							"logging.file":      knownvalue.StringExact("/var/log/sampleapp.log"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestExternalDataSource_basic_json(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_json_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_external", fmt.Sprintf(`
+	source     = file(%q)
+	input_type = "json"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sops_external.test",
+						tfjsonpath.New("data"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"abc":      knownvalue.StringExact("xyz"),
+							"integers": knownvalue.StringExact("123"),
+							"truthy":   knownvalue.StringExact("true"),
+							"floats":   knownvalue.StringExact("3.14e-10"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestExternalDataSource_extract(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_json_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_external", fmt.Sprintf(`
+	source     = file(%q)
+	input_type = "json"
+	extract    = "/abc"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sops_external.test",
+						tfjsonpath.New("raw"),
+						knownvalue.StringExact("xyz"),
+					),
+					statecheck.ExpectKnownValue(
+						"data.sops_external.test",
+						tfjsonpath.New("data"),
+						knownvalue.MapExact(map[string]knownvalue.Check{}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestExternalDataSource_invalid_input_type(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_json_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_external", fmt.Sprintf(`
+	source     = file(%q)
+	input_type = "foobar"
+`, fixture)),
+				ExpectError: regexp.MustCompile("invalid input type: foobar"),
+			},
+		},
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+var _ function.Function = &extractFunction{}
+
+type extractFunction struct{}
+
+func NewExtractFunction() function.Function {
+	return &extractFunction{}
+}
+
+func (f *extractFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "extract"
+}
+
+func (f *extractFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Reads and decrypts a single value of a sops encrypted file.",
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Reads and decrypts a [sops](https://getsops.io/) encrypted file and returns only the value at
+			the given path, similar to ` + utils.Code("sops --extract") + `. Only the extracted value is
+			converted into a Terraform value, so the remaining decrypted data cannot leak into plan output.
+
+			The path can either be given in the sops syntax, e.g. ` + utils.Code(`["database"]["hosts"][0]`) + `,
+			or as a JSON Pointer, e.g. ` + utils.Code("/database/hosts/0") + `. Objects and arrays are
+			returned as such, all other values are returned as string. If the path does not exist, an error
+			naming the missing segment is returned.
+
+			An optional format can be provided to specify the format of the encrypted file. If not provided,
+			we will try to infer the format from the file extension. Supported formats are ` +
+			utils.Code("yaml") + `, ` + utils.Code("json") + `, ` + utils.Code("dotenv") + `, and ` +
+			utils.Code("ini") + `.
+
+			Decryption is based on the sops library, so it will use the same heuristics and key sources
+			as sops to attempt to decrypt the data.
+			`)),
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "file",
+				MarkdownDescription: "The path to the sops encrypted file.",
+			},
+			function.StringParameter{
+				Name:                "path",
+				MarkdownDescription: "The path of the value to extract, either in the sops syntax, e.g. `[\"a\"][0]`, or as a JSON Pointer, e.g. `/a/0`.",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name:           "format",
+			Description:    "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, and `ini`. Optional.",
+			AllowNullValue: true,
+		},
+
+		Return: function.DynamicReturn{},
+	}
+}
+
+func (f *extractFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var file string
+	var extractPath string
+	var varargs []string
+
+	resp.Error = req.Arguments.Get(ctx, &file, &extractPath, &varargs)
+	if resp.Error != nil {
+		return
+	}
+
+	decryptReq := decryptRequest{
+		source:     file,
+		sourceType: sourceTypePath,
+		extract:    extractPath,
+	}
+	if len(varargs) > 0 {
+		decryptReq.format = varargs[0]
+	}
+
+	result, funcErr := decryptReq.run(ctx)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, result.Attributes()["data"])
+}
@@ -0,0 +1,85 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestExtractFunction_complex_yaml(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperOptionsFunctionConfig("extract", fmt.Sprintf("%q", fixture), `"[\"object_key\"][\"nested_list\"][0]"`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact("nested item1")),
+				},
+			},
+			{
+				Config: testHelperOptionsFunctionConfig("extract", fmt.Sprintf("%q", fixture), `"/integer_key"`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact("42")),
+				},
+			},
+			{
+				Config: testHelperOptionsFunctionConfig("extract", fmt.Sprintf("%q", fixture), `"/object_key/nested_object"`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"deeper_string":  knownvalue.StringExact("deeper example"),
+							"deeper_boolean": knownvalue.Bool(false),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestExtractFunction_path_not_found(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testHelperOptionsFunctionConfig("extract", fmt.Sprintf("%q", fixture), `"/object_key/missing"`),
+				ExpectError: regexp.MustCompile(`path not found: key "missing" does not exist in \["object_key"\]`),
+			},
+			{
+				Config:      testHelperOptionsFunctionConfig("extract", fmt.Sprintf("%q", fixture), `"object_key.missing"`),
+				ExpectError: regexp.MustCompile("invalid path"),
+			},
+		},
+	})
+}
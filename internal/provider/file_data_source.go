@@ -0,0 +1,215 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that fileDataSource implements the DataSource interfaces.
+var _ datasource.DataSource = &fileDataSource{}
+var _ datasource.DataSourceWithConfigure = &fileDataSource{}
+
+type fileDataSource struct {
+	providerData *sopsProviderData
+}
+
+type fileDataSourceModel struct {
+	SourceFile types.String `tfsdk:"source_file"`
+	InputType  types.String `tfsdk:"input_type"`
+	Extract    types.String `tfsdk:"extract"`
+	ID         types.String `tfsdk:"id"`
+	Data       types.Map    `tfsdk:"data"`
+	Raw        types.String `tfsdk:"raw"`
+}
+
+func NewFileDataSource() datasource.DataSource {
+	return &fileDataSource{}
+}
+
+func (d *fileDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file"
+}
+
+func (d *fileDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Reads and decrypts a [sops](https://getsops.io/) encrypted file. This data source is compatible
+			with the ` + utils.Code("sops_file") + ` data source of
+			[carlpett/terraform-provider-sops](https://github.com/carlpett/terraform-provider-sops), so
+			existing configurations can be migrated by only changing the provider source.
+
+			The decrypted data is returned as a flat map in the ` + utils.Code("data") + ` attribute, with
+			nested keys joined by dots and list elements addressed by their index, e.g. ` +
+			utils.Code(`data["database.hosts.0"]`) + `. The raw decrypted data is returned in the ` +
+			utils.Code("raw") + ` attribute.
+
+			~> **Note:** The decrypted data is stored in the Terraform state. Prefer the ` +
+			utils.Code("sops_file") + ` ephemeral resource or the provider functions for new configurations.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"source_file": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops encrypted file.",
+				Required:            true,
+			},
+			"input_type": schema.StringAttribute{
+				MarkdownDescription: "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `raw`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+			},
+			"extract": schema.StringAttribute{
+				MarkdownDescription: "The path of a single value to return instead of the whole document, either in the sops syntax, e.g. `[\"a\"][0]`, or as a JSON Pointer, e.g. `/a/0`. If set, `raw` and `data` only contain the extracted value, `data` is empty for scalar values.",
+				Optional:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Always `-`, for compatibility.",
+				Computed:            true,
+			},
+			"data": schema.MapAttribute{
+				MarkdownDescription: "The decrypted data as a flat map of strings. Empty for `raw`.",
+				ElementType:         types.StringType,
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"raw": schema.StringAttribute{
+				MarkdownDescription: "The raw decrypted data.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (d *fileDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *fileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data fileDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sourceFile := data.SourceFile.ValueString()
+
+	// infer format from file extension if not explicitly provided, like carlpett does
+	inputType := data.InputType.ValueString()
+	if inputType == "" {
+		switch ext := filepath.Ext(sourceFile); ext {
+		case ".json":
+			inputType = "json"
+		case ".yaml", ".yml":
+			inputType = "yaml"
+		case ".env":
+			inputType = "dotenv"
+		case ".ini":
+			inputType = "ini"
+		default:
+			resp.Diagnostics.AddAttributeError(
+				path.Root("input_type"),
+				"Unknown file extension",
+				fmt.Sprintf("don't know how to decode file with extension %s, set input_type as appropriate", ext),
+			)
+			return
+		}
+	}
+
+	format, ok := formatFromInputType(inputType)
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("input_type"),
+			"Invalid input type",
+			fmt.Sprintf("invalid input type: %s", inputType),
+		)
+		return
+	}
+
+	decryptReq := decryptRequest{
+		source:     sourceFile,
+		sourceType: sourceTypePath,
+		format:     format,
+		extract:    data.Extract.ValueString(),
+		keys:       d.providerData.decryptOptions().Keys,
+	}
+
+	data.ID = types.StringValue("-")
+	data.Raw, data.Data = decryptFlattened(ctx, decryptReq, "Failed to decrypt file", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// formatFromInputType returns the format for the given input type of the carlpett data sources,
+// which call the binary format raw.
+func formatFromInputType(inputType string) (string, bool) {
+	if inputType == "raw" {
+		return "binary", true
+	}
+
+	return inputType, utils.IsValidFormat(inputType)
+}
+
+// decryptFlattened runs the given request and returns the raw decrypted data and the decrypted
+// data as flat map of strings, as the carlpett data sources do. If a value is extracted, the map
+// contains the flattened extracted value, or nothing if it is a scalar.
+func decryptFlattened(ctx context.Context, req decryptRequest, summary string, diags *diag.Diagnostics) (types.String, types.Map) {
+	decrypted := req.runWithDiagnostics(ctx, summary, diags)
+	if diags.HasError() {
+		return types.StringNull(), types.MapNull(types.StringType)
+	}
+
+	raw := []byte(decrypted.Raw.ValueString())
+
+	switch {
+	case req.extract == "":
+		return decrypted.Raw, flattenedData(ctx, raw, req.format, diags)
+	case isDynamicString(decrypted.Data):
+		return decrypted.Raw, types.MapValueMust(types.StringType, map[string]attr.Value{})
+	default:
+		// extracted objects and arrays are returned as JSON
+		return decrypted.Raw, flattenedData(ctx, raw, "json", diags)
+	}
+}
+
+// isDynamicString reports whether the given dynamic value is a string.
+func isDynamicString(value types.Dynamic) bool {
+	_, ok := value.UnderlyingValue().(types.String)
+	return ok
+}
+
+// flattenedData returns the decrypted data as flat map of strings, as the carlpett data sources do.
+func flattenedData(ctx context.Context, cleartext []byte, format string, diags *diag.Diagnostics) types.Map {
+	json, err := utils.UnmarshalDecryptedData(cleartext, format)
+	if err != nil {
+		diags.AddError("Failed to unmarshal decrypted data", fmt.Sprintf("failed to unmarshal decrypted data: %v", err))
+		return types.MapNull(types.StringType)
+	}
+
+	flattened, err := utils.Flatten(json)
+	if err != nil {
+		diags.AddError("Failed to flatten decrypted data", fmt.Sprintf("failed to flatten decrypted data: %v", err))
+		return types.MapNull(types.StringType)
+	}
+
+	data, d := types.MapValueFrom(ctx, types.StringType, flattened)
+	diags.Append(d...)
+
+	return data
+}
@@ -0,0 +1,180 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestFileDataSource_complex_yaml(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_file", fmt.Sprintf(`source_file = %q`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sops_file.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact("-"),
+					),
+					statecheck.ExpectKnownValue(
+						"data.sops_file.test",
+						tfjsonpath.New("data"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"string_key":                             knownvalue.StringExact("example string"),
+							"integer_key":                            knownvalue.StringExact("42"),
+							"float_key":                              knownvalue.StringExact("3.14"),
+							"boolean_key":                            knownvalue.StringExact("true"),
+							"null_key":                               knownvalue.StringExact("null"),
+							"list_key.0":                             knownvalue.StringExact("item1"),
+							"list_key.1":                             knownvalue.StringExact("item2"),
+							"list_key.2":                             knownvalue.StringExact("3"),
+							"list_key.3":                             knownvalue.StringExact("false"),
+							"list_key.4":                             knownvalue.StringExact("null"),
+							"object_key.nested_string":               knownvalue.StringExact("nested example"),
+							"object_key.nested_integer":              knownvalue.StringExact("100"),
+							"object_key.nested_list.0":               knownvalue.StringExact("nested item1"),
+							"object_key.nested_list.1":               knownvalue.StringExact("200"),
+							"object_key.nested_object.deeper_string": knownvalue.StringExact("deeper example"),
+							"object_key.nested_object.deeper_boolean": knownvalue.StringExact("false"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestFileDataSource_extract(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_file", fmt.Sprintf(`
+	source_file = %q
+	extract     = "/object_key/nested_object"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sops_file.test",
+						tfjsonpath.New("data"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"deeper_string":  knownvalue.StringExact("deeper example"),
+							"deeper_boolean": knownvalue.StringExact("false"),
+						}),
+					),
+				},
+			},
+			{
+				Config: testHelperDataSourceConfig("sops_file", fmt.Sprintf(`
+	source_file = %q
+	extract     = "[\"list_key\"]"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sops_file.test",
+						tfjsonpath.New("data"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"0": knownvalue.StringExact("item1"),
+							"1": knownvalue.StringExact("item2"),
+							"2": knownvalue.StringExact("3"),
+							"3": knownvalue.StringExact("false"),
+							"4": knownvalue.StringExact("null"),
+						}),
+					),
+				},
+			},
+			{
+				Config: testHelperDataSourceConfig("sops_file", fmt.Sprintf(`
+	source_file = %q
+	extract     = "/missing"
+`, fixture)),
+				ExpectError: regexp.MustCompile(`path not found`),
+			},
+		},
+	})
+}
+
+func TestFileDataSource_raw(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_raw_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_file", fmt.Sprintf(`
+	source_file = %q
+	input_type  = "raw"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sops_file.test",
+						tfjsonpath.New("raw"),
+						knownvalue.StringExact("Lorem ipsum dolor sit amet, consectetur adipiscing elit.\n"),
+					),
+					statecheck.ExpectKnownValue(
+						"data.sops_file.test",
+						tfjsonpath.New("data"),
+						knownvalue.MapExact(map[string]knownvalue.Check{}),
+					),
+				},
+			},
+			{
+				Config: testHelperDataSourceConfig("sops_file", fmt.Sprintf(`source_file = %q`, fixture)),
+				ExpectError: regexp.MustCompile(
+					"don't know how to decode file with extension .txt",
+				),
+			},
+		},
+	})
+}
+
+func TestFileDataSource_basic_mac_mismatch(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_mac_mismatch_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperDataSourceConfig("sops_file", fmt.Sprintf(`source_file = %q`, fixture)),
+				ExpectError: regexp.MustCompile(
+					".*failed to verify data integrity.*",
+				),
+			},
+		},
+	})
+}
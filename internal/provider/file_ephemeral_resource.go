@@ -0,0 +1,143 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that fileEphemeralResource implements the EphemeralResource interfaces.
+var _ ephemeral.EphemeralResource = &fileEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &fileEphemeralResource{}
+
+type fileEphemeralResource struct {
+	providerData *sopsProviderData
+}
+
+type fileEphemeralResourceModel struct {
+	File      types.String  `tfsdk:"file"`
+	Format    types.String  `tfsdk:"format"`
+	IgnoreMac types.Bool    `tfsdk:"ignore_mac"`
+	Extract   types.String  `tfsdk:"extract"`
+	Raw       types.String  `tfsdk:"raw"`
+	Data      types.Dynamic `tfsdk:"data"`
+}
+
+func NewFileEphemeralResource() ephemeral.EphemeralResource {
+	return &fileEphemeralResource{}
+}
+
+func (r *fileEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file"
+}
+
+func (r *fileEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Reads and decrypts a [sops](https://getsops.io/) encrypted file without persisting the
+			decrypted data in plan or state. An optional format can be provided to specify the format of
+			the encrypted file. If not provided, we will try to infer the format from the file extension.
+			Supported formats are ` + utils.Code("yaml") + `, ` + utils.Code("json") + `, ` +
+			utils.Code("dotenv") + `, ` + utils.Code("ini") + `, and ` + utils.Code("binary") + `.
+
+			If the file format is any of the supported formats other than ` + utils.Code("binary") + `, the
+			decrypted data will also be returned as an object in the ` + utils.Code("data") + ` attribute.
+			Regardless of the format, the raw decrypted data will always be returned in the ` +
+			utils.Code("raw") + ` attribute.
+
+			Ephemeral resources are available in Terraform v1.10 and later. Their results can only be
+			referenced from other ephemeral contexts, such as provider configuration blocks, write-only
+			attributes, or other ephemeral resources.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"file": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops encrypted file.",
+				Required:            true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"ignore_mac": schema.BoolAttribute{
+				MarkdownDescription: "Whether to ignore a MAC mismatch when decrypting the file. Defaults to `false`.",
+				Optional:            true,
+			},
+			"extract": schema.StringAttribute{
+				MarkdownDescription: "The path of a single value to return instead of the whole document, either in the sops syntax, e.g. `[\"a\"][0]`, or as a JSON Pointer, e.g. `/a/0`. If set, `raw` and `data` only contain the extracted value.",
+				Optional:            true,
+			},
+			"raw": schema.StringAttribute{
+				MarkdownDescription: "The raw decrypted data.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+			"data": schema.DynamicAttribute{
+				MarkdownDescription: "The decrypted data as an object, if the format supports it. `null` for `binary`.",
+				Computed:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *fileEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	r.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *fileEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data fileEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	file := data.File.ValueString()
+
+	// infer format from file extension if not explicitly provided
+	format := data.Format.ValueString()
+	if format == "" {
+		format = utils.FileFormatFromPath(file)
+	}
+
+	if !utils.IsValidFormat(format) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+		return
+	}
+
+	decryptReq := decryptRequest{
+		source:     file,
+		sourceType: sourceTypePath,
+		format:     format,
+		ignoreMAC:  data.IgnoreMac.ValueBool(),
+		extract:    data.Extract.ValueString(),
+		keys:       r.providerData.decryptOptions().Keys,
+	}
+
+	decrypted := decryptReq.runWithDiagnostics(ctx, "Failed to decrypt file", &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Format = types.StringValue(format)
+	data.Raw = decrypted.Raw
+	data.Data = decrypted.Data
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
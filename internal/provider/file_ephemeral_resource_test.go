@@ -0,0 +1,271 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestFileEphemeralResource_raw(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_raw_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("raw"),
+						knownvalue.StringExact("Lorem ipsum dolor sit amet, consectetur adipiscing elit.\n"),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("format"),
+						knownvalue.StringExact("binary"),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data"),
+						knownvalue.Null(),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestFileEphemeralResource_complex_yaml(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("format"),
+						knownvalue.StringExact("yaml"),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"string_key":  knownvalue.StringExact("example string"),
+							"integer_key": knownvalue.Int64Exact(42),
+							"float_key":   knownvalue.Float64Exact(3.14),
+							"boolean_key": knownvalue.Bool(true),
+							"null_key":    knownvalue.Null(),
+							"list_key": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.StringExact("item1"),
+								knownvalue.StringExact("item2"),
+								knownvalue.Int64Exact(3),
+								knownvalue.Bool(false),
+								knownvalue.Null(),
+							}),
+							"object_key": knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"nested_string":  knownvalue.StringExact("nested example"),
+								"nested_integer": knownvalue.Int64Exact(100),
+								"nested_list": knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.StringExact("nested item1"),
+									knownvalue.Int64Exact(200),
+								}),
+								"nested_object": knownvalue.ObjectExact(map[string]knownvalue.Check{
+									"deeper_string":  knownvalue.StringExact("deeper example"),
+									"deeper_boolean": knownvalue.Bool(false),
+								}),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestFileEphemeralResource_extract(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`
+	file    = %q
+	extract = "[\"object_key\"][\"nested_object\"]"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"deeper_string":  knownvalue.StringExact("deeper example"),
+							"deeper_boolean": knownvalue.Bool(false),
+						}),
+					),
+				},
+			},
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`
+	file    = %q
+	extract = "/object_key/nested_integer"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("raw"),
+						knownvalue.StringExact("100"),
+					),
+				},
+			},
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`
+	file    = %q
+	extract = "/object_key/missing"
+`, fixture)),
+				ExpectError: regexp.MustCompile(`path not found: key "missing" does not exist`),
+			},
+		},
+	})
+}
+
+func TestFileEphemeralResource_sample_env(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_sample_env_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`
+	file   = %q
+	format = "dotenv"
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("format"),
+						knownvalue.StringExact("dotenv"),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"HELLO":        knownvalue.StringExact("world"),
+							"SOME_NUMBER":  knownvalue.StringExact("42"),
+							"DATABASE_URL": knownvalue.StringExact("postgres://localhost:5432/mydb"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestFileEphemeralResource_basic_mac_mismatch(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_mac_mismatch_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ExpectError: regexp.MustCompile(
+					".*failed to verify data integrity.*",
+				),
+			},
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`
+	file       = %q
+	ignore_mac = true
+`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data"),
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"abc": knownvalue.StringExact("xyz"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestFileEphemeralResource_invalid_format(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_file", fmt.Sprintf(`
+	file   = %q
+	format = "foobar"
+`, fixture)),
+				ExpectError: regexp.MustCompile("invalid format:.*"),
+			},
+		},
+	})
+}
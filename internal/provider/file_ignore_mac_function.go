@@ -5,21 +5,13 @@ package provider
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/function"
-	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/lithammer/dedent"
 	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
 )
 
-var sopsFileIgnoreMacReturnAttrTypes = map[string]attr.Type{
-	"raw":  types.StringType,
-	"data": types.DynamicType,
-}
-
 // Ensure that fileIgnoreMacFunction implements the Function interface.
 var _ function.Function = &fileIgnoreMacFunction{}
 
@@ -65,7 +57,7 @@ func (f *fileIgnoreMacFunction) Definition(ctx context.Context, req function.Def
 		},
 
 		Return: function.ObjectReturn{
-			AttributeTypes: sopsFileIgnoreMacReturnAttrTypes,
+			AttributeTypes: sopsDecryptReturnAttrTypes,
 		},
 	}
 }
@@ -79,50 +71,14 @@ func (f *fileIgnoreMacFunction) Run(ctx context.Context, req function.RunRequest
 		return
 	}
 
-	// infer format from file extension if not explicitly provided
-	format := ""
-	if len(varargs) > 0 {
-		format = varargs[0]
-	} else {
-		format = utils.FileFormatFromPath(file)
-	}
-
-	if !utils.IsValidFormat(format) {
-		resp.Error = function.NewFuncError(fmt.Sprintf("invalid format: %s", format))
-		return
+	decryptReq := decryptRequest{
+		source:     file,
+		sourceType: sourceTypePath,
+		ignoreMAC:  true,
 	}
-
-	// decrypt sops file
-	cleartext, err := utils.DecryptFile(file, format, utils.DecryptOptions{IgnoreMACMismatch: true})
-	if err != nil {
-		resp.Error = function.NewFuncError(fmt.Sprintf("failed to decrypt file: %v", err))
-		return
-	}
-
-	json, err := utils.UnmarshalDecryptedData(cleartext, format)
-	if err != nil {
-		resp.Error = function.NewFuncError(fmt.Sprintf("failed to unmarshal decrypted data: %v", err))
-		return
-	}
-
-	dynamicData, err := utils.JSONToDynamicImplied(json)
-	if err != nil {
-		resp.Error = function.NewFuncError(fmt.Sprintf("failed to convert decrypted data to dynamic data: %v", err))
-		return
-	}
-
-	result, diags := types.ObjectValue(
-		sopsFileIgnoreMacReturnAttrTypes,
-		map[string]attr.Value{
-			"raw":  types.StringValue(string(cleartext)),
-			"data": dynamicData,
-		},
-	)
-
-	resp.Error = function.FuncErrorFromDiags(ctx, diags)
-	if resp.Error != nil {
-		return
+	if len(varargs) > 0 {
+		decryptReq.format = varargs[0]
 	}
 
-	resp.Error = resp.Result.Set(ctx, &result)
+	runDecryptRequest(ctx, decryptReq, resp)
 }
@@ -0,0 +1,134 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that fileListResource implements the ListResource interface.
+var _ list.ListResource = &fileListResource{}
+
+type fileListResource struct{}
+
+type fileListResourceModel struct {
+	Directory types.String `tfsdk:"directory"`
+	Include   types.List   `tfsdk:"include"`
+	Exclude   types.List   `tfsdk:"exclude"`
+}
+
+func NewFileListResource() list.ListResource {
+	return &fileListResource{}
+}
+
+func (r *fileListResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file"
+}
+
+func (r *fileListResource) ListResourceConfigSchema(ctx context.Context, req list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Discovers [sops](https://getsops.io/) encrypted files below a directory, e.g. to inventory
+			all secrets of a repository with ` + utils.Code("terraform query") + `. Files are recognized
+			by loading them with the sops store for the format inferred from their extension, they are
+			never decrypted, so no keys are required.
+
+			Globs are matched against the slash-separated path relative to ` + utils.Code("directory") + `
+			and support ` + utils.Code("*") + `, ` + utils.Code("?") + `, character classes, and
+			` + utils.Code("**") + ` to match any number of directories. Directories excluded by a glob
+			ending in ` + utils.Code("/**") + `, e.g. ` + utils.Code("vendor/**") + `, and ` + utils.Code(".git") + ` directories are not
+			searched at all. Files larger than 16 MiB are skipped, files and directories that cannot be
+			read are reported as warnings.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"directory": schema.StringAttribute{
+				MarkdownDescription: "The directory to search for encrypted files.",
+				Required:            true,
+			},
+			"include": schema.ListAttribute{
+				MarkdownDescription: "Globs of files to include, e.g. `**/*.enc.yaml`. If not provided, all files are included.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"exclude": schema.ListAttribute{
+				MarkdownDescription: "Globs of files to exclude, e.g. `vendor/**`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *fileListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var data fileListResourceModel
+	var include, exclude []string
+
+	diags := req.Config.Get(ctx, &data)
+	diags.Append(data.Include.ElementsAs(ctx, &include, false)...)
+	diags.Append(data.Exclude.ElementsAs(ctx, &exclude, false)...)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	files, warnings, err := utils.FindEncryptedFiles(data.Directory.ValueString(), include, exclude)
+	if err != nil {
+		diags.AddError("Failed to find encrypted files", fmt.Sprintf("failed to find encrypted files in %q: %v", data.Directory.ValueString(), err))
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	if req.Limit > 0 && int64(len(files)) > req.Limit {
+		files = files[:req.Limit]
+	}
+
+	for _, warning := range warnings {
+		diags.AddWarning("Skipped unreadable file", warning.Error())
+	}
+
+	stream.Results = func(yield func(list.ListResult) bool) {
+		// warnings are reported in a result of their own, before the files
+		if len(diags) > 0 && !yield(list.ListResult{Diagnostics: diags}) {
+			return
+		}
+
+		for _, file := range files {
+			if !yield(r.listResult(ctx, req, file)) {
+				return
+			}
+		}
+	}
+}
+
+// listResult returns the list result of the given encrypted file, including the full resource if
+// requested.
+func (r *fileListResource) listResult(ctx context.Context, req list.ListRequest, file utils.EncryptedFile) list.ListResult {
+	result := req.NewListResult(ctx)
+	result.DisplayName = file.Path
+
+	filePath := types.StringValue(file.Path)
+	result.Diagnostics.Append(result.Identity.Set(ctx, fileResourceIdentityModel{Path: filePath})...)
+
+	if req.IncludeResource {
+		model := fileResourceModel{
+			Path:   filePath,
+			Format: types.StringValue(file.Format),
+		}
+
+		result.Diagnostics.Append(model.setMetadata(file.Metadata, nil)...)
+		result.Diagnostics.Append(result.Resource.Set(ctx, &model)...)
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/querycheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestFileListResource_basic(t *testing.T) {
+	dir := t.TempDir()
+
+	// only the encrypted YAML files outside of vendor are listed
+	for name, data := range map[string][]byte{
+		"basic.sops.yaml":          mustReadTestFile(t, fixture_basic_yaml_file),
+		"nested/complex.sops.yaml": mustReadTestFile(t, fixture_complex_yaml_file),
+		"nested/basic.sops.json":   mustReadTestFile(t, fixture_basic_json_file),
+		"plain.yaml":               []byte("abc: xyz\n"),
+		"vendor/basic.sops.yaml":   mustReadTestFile(t, fixture_basic_yaml_file),
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no key material must be needed to discover encrypted files
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Query: true,
+				Config: fmt.Sprintf(`
+provider "sops" {}
+
+list "sops_file" "test" {
+	provider = sops
+
+	config {
+		directory = %q
+		include   = ["**/*.yaml"]
+		exclude   = ["vendor/**"]
+	}
+}
+`, dir),
+				QueryResultChecks: []querycheck.QueryResultCheck{
+					querycheck.ExpectLength("sops_file.test", 2),
+					querycheck.ExpectIdentity("sops_file.test", map[string]knownvalue.Check{
+						"path": knownvalue.StringExact(filepath.Join(dir, "basic.sops.yaml")),
+					}),
+					querycheck.ExpectIdentity("sops_file.test", map[string]knownvalue.Check{
+						"path": knownvalue.StringExact(filepath.Join(dir, "nested", "complex.sops.yaml")),
+					}),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,260 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that fileResource implements the Resource interfaces.
+var _ resource.Resource = &fileResource{}
+var _ resource.ResourceWithIdentity = &fileResource{}
+var _ resource.ResourceWithImportState = &fileResource{}
+var _ resource.ResourceWithModifyPlan = &fileResource{}
+
+type fileResource struct{}
+
+type fileResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	Path         types.String `tfsdk:"path"`
+	Format       types.String `tfsdk:"format"`
+	LastModified types.String `tfsdk:"lastmodified"`
+	Recipients   types.List   `tfsdk:"recipients"`
+}
+
+type fileResourceIdentityModel struct {
+	Path types.String `tfsdk:"path"`
+}
+
+func NewFileResource() resource.Resource {
+	return &fileResource{}
+}
+
+func (r *fileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file"
+}
+
+func (r *fileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Tracks an existing [sops](https://getsops.io/) encrypted file and its metadata, without
+			decrypting it. The file itself is neither written nor deleted by this resource, destroying
+			it only removes it from the state.
+
+			This resource is mainly used together with the ` + utils.Code("sops_file") + ` list resource, so
+			that the encrypted files found by ` + utils.Code("terraform query") + ` can be imported into
+			the state as an inventory.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The path of the encrypted file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops encrypted file.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"lastmodified": schema.StringAttribute{
+				MarkdownDescription: "The time the file was last modified by sops, as RFC3339 timestamp.",
+				Computed:            true,
+			},
+			"recipients": schema.ListNestedAttribute{
+				MarkdownDescription: "The master keys of all key groups the file is encrypted for.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							MarkdownDescription: "The sops key type, e.g. `age`, `pgp`, or `kms`.",
+							Computed:            true,
+						},
+						"identifier": schema.StringAttribute{
+							MarkdownDescription: "The identifier of the key, e.g. the age recipient, the PGP fingerprint, or the KMS ARN.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *fileResource) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"path": identityschema.StringAttribute{
+				Description:       "The path to the sops encrypted file.",
+				RequiredForImport: true,
+			},
+		},
+	}
+}
+
+func (r *fileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan fileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// infer format from file extension if not explicitly provided, so that it is known during plan
+	if plan.Format.IsUnknown() && !plan.Path.IsUnknown() {
+		format := utils.FileFormatFromPath(plan.Path.ValueString())
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("format"), types.StringValue(format))...)
+	}
+}
+
+func (r *fileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data fileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.refresh()...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, fileResourceIdentityModel{Path: data.Path})...)
+}
+
+func (r *fileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data fileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the format is not known yet after an import
+	if data.Format.IsNull() {
+		data.Format = types.StringValue(utils.FileFormatFromPath(data.Path.ValueString()))
+	}
+
+	metadata, err := utils.ReadMetadataFile(data.Path.ValueString(), data.Format.ValueString())
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(data.setMetadata(metadata, err)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(resp.Identity.Set(ctx, fileResourceIdentityModel{Path: data.Path})...)
+}
+
+func (r *fileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data fileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.refresh()...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *fileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// the file is not owned by this resource, so it is only removed from the state
+}
+
+func (r *fileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughWithIdentity(ctx, path.Root("path"), path.Root("path"), req, resp)
+}
+
+// refresh reads the metadata of the file of the model and updates its computed attributes.
+func (m *fileResourceModel) refresh() diag.Diagnostics {
+	metadata, err := utils.ReadMetadataFile(m.Path.ValueString(), m.Format.ValueString())
+	return m.setMetadata(metadata, err)
+}
+
+// setMetadata updates the computed attributes of the model from the given metadata, adding an
+// error diagnostic if reading the metadata failed.
+func (m *fileResourceModel) setMetadata(metadata utils.Metadata, err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("path"),
+			"Failed to read sops metadata",
+			fmt.Sprintf("failed to read sops metadata of %q: %v", m.Path.ValueString(), err),
+		)
+		return diags
+	}
+
+	m.ID = m.Path
+	m.LastModified = types.StringValue(metadata.LastModified.UTC().Format(time.RFC3339))
+	m.Recipients, diags = recipientsList(metadata)
+
+	return diags
+}
+
+// recipientsList returns the master keys of all key groups of the given metadata as list of
+// objects.
+func recipientsList(metadata utils.Metadata) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	recipients := []attr.Value{}
+	for _, group := range metadata.KeyGroups {
+		for _, key := range group {
+			recipient, d := types.ObjectValue(sopsMasterKeyAttrTypes, map[string]attr.Value{
+				"type":       types.StringValue(key.Type),
+				"identifier": types.StringValue(key.Identifier),
+			})
+			diags.Append(d...)
+
+			recipients = append(recipients, recipient)
+		}
+	}
+
+	list, d := types.ListValue(types.ObjectType{AttrTypes: sopsMasterKeyAttrTypes}, recipients)
+	diags.Append(d...)
+
+	return list, diags
+}
@@ -0,0 +1,94 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestFileResource_basic(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	// no key material must be needed to read the metadata
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperResourceConfig("sops_file", fmt.Sprintf("\tpath = %q\n", fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"sops_file.test",
+						tfjsonpath.New("format"),
+						knownvalue.StringExact("yaml"),
+					),
+					statecheck.ExpectKnownValue(
+						"sops_file.test",
+						tfjsonpath.New("lastmodified"),
+						knownvalue.StringExact("2024-11-27T20:58:06Z"),
+					),
+					statecheck.ExpectKnownValue(
+						"sops_file.test",
+						tfjsonpath.New("recipients"),
+						knownvalue.ListExact([]knownvalue.Check{
+							knownvalue.ObjectExact(map[string]knownvalue.Check{
+								"type":       knownvalue.StringExact("age"),
+								"identifier": knownvalue.StringExact(test_age_recipient),
+							}),
+						}),
+					),
+					statecheck.ExpectIdentity(
+						"sops_file.test",
+						map[string]knownvalue.Check{
+							"path": knownvalue.StringExact(fixture),
+						},
+					),
+				},
+			},
+			{
+				ResourceName:    "sops_file.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+func TestFileResource_invalid(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testHelperResourceConfig("sops_file", fmt.Sprintf("\tpath = %q\n", wd+"/file_resource.go")),
+				ExpectError: regexp.MustCompile("Failed to read sops metadata"),
+			},
+		},
+	})
+}
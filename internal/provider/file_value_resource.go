@@ -0,0 +1,327 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that fileValueResource implements the Resource interfaces.
+var _ resource.Resource = &fileValueResource{}
+var _ resource.ResourceWithConfigure = &fileValueResource{}
+var _ resource.ResourceWithModifyPlan = &fileValueResource{}
+var _ resource.ResourceWithValidateConfig = &fileValueResource{}
+
+type fileValueResource struct {
+	providerData *sopsProviderData
+}
+
+type fileValueResourceModel struct {
+	ID       types.String  `tfsdk:"id"`
+	Filename types.String  `tfsdk:"filename"`
+	Format   types.String  `tfsdk:"format"`
+	Path     types.String  `tfsdk:"path"`
+	Value    types.Dynamic `tfsdk:"value"`
+}
+
+func NewFileValueResource() resource.Resource {
+	return &fileValueResource{}
+}
+
+func (r *fileValueResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_file_value"
+}
+
+func (r *fileValueResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Manages a single value inside an existing [sops](https://getsops.io/) encrypted file, like
+			` + utils.Code("sops set") + ` and ` + utils.Code("sops unset") + `, e.g. to add a generated
+			database password to a secrets file that is otherwise maintained by hand. The file is
+			decrypted with the key material of the provider, the value is inserted or updated, and the
+			file is encrypted again with the same data key. All other values and the metadata of the
+			file are preserved and its MAC is recomputed. Destroying the resource removes the value
+			from the file again.
+
+			Changes to the value made outside of Terraform are detected by decrypting the file. If the
+			value was removed, it is added again. Resources changing the same file are serialized by a
+			lock on the file ` + utils.Code("<filename>.lock") + `, also across provider aliases and concurrent Terraform runs,
+			so that they do not overwrite each other's changes.
+
+			~> **Note:** The value is stored in the Terraform state, like any other argument. Protect
+			access to Terraform state accordingly.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The path of the encrypted file and the path of the value within it, separated by a colon.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"filename": schema.StringAttribute{
+				MarkdownDescription: "The path to the existing sops encrypted file to set the value in.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "The format of the file. Supported formats are `yaml`, `json`, `dotenv`, and `ini`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "The path of the value within the file, either in the sops syntax, e.g. `[\"database\"][\"password\"]`, or as a JSON Pointer, e.g. `/database/password`. Missing objects along the path are created. An array index equal to the length of the array appends the value.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"value": schema.DynamicAttribute{
+				MarkdownDescription: "The value to set, either a primitive value or an object, map, list, or tuple. Only strings can be set in `dotenv` and `ini` files.",
+				Required:            true,
+				Sensitive:           true,
+			},
+		},
+	}
+}
+
+func (r *fileValueResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *fileValueResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data fileValueResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Path.IsUnknown() {
+		if _, err := utils.ParseExtractPath(data.Path.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("path"),
+				"Invalid path",
+				err.Error(),
+			)
+		}
+	}
+
+	switch format := data.Format.ValueString(); {
+	case format == "binary":
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			"values cannot be set in binary files",
+		)
+	case format != "" && !utils.IsValidFormat(format):
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+	}
+
+	// dotenv and ini files only contain strings, so any other value would differ when read back
+	format := data.Format.ValueString()
+	if format == "" && !data.Filename.IsUnknown() {
+		format = utils.FileFormatFromPath(data.Filename.ValueString())
+	}
+
+	if (format == "dotenv" || format == "ini") && !data.Format.IsUnknown() &&
+		!data.Value.IsUnknown() && !data.Value.IsUnderlyingValueUnknown() {
+		if _, ok := data.Value.UnderlyingValue().(types.String); !ok {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("value"),
+				"Invalid value",
+				fmt.Sprintf("only string values can be set in %s files", format),
+			)
+		}
+	}
+}
+
+func (r *fileValueResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan fileValueResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// infer format from file extension if not explicitly provided, so that it is known during plan
+	if plan.Format.IsUnknown() && !plan.Filename.IsUnknown() {
+		format := utils.FileFormatFromPath(plan.Filename.ValueString())
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("format"), types.StringValue(format))...)
+	}
+}
+
+func (r *fileValueResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data fileValueResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.set(ctx, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue(data.Filename.ValueString() + ":" + data.Path.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *fileValueResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data fileValueResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+
+	encrypted, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", fmt.Sprintf("failed to read %q: %v", filename, err))
+		return
+	}
+
+	actual, found, err := utils.GetValue(encrypted, data.Format.ValueString(), data.Path.ValueString(), r.providerData.decryptOptions())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read value", fmt.Sprintf("failed to read value at %s of %q: %v", data.Path.ValueString(), filename, err))
+		return
+	}
+
+	// the value was removed outside of Terraform, so it has to be set again
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	expected, err := data.valueJSON(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to convert value", err.Error())
+		return
+	}
+
+	// the value was changed outside of Terraform, record it so that the change shows up in the plan
+	if !bytes.Equal(actual, expected) {
+		data.Value, err = utils.JSONToDynamicImplied(actual)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to convert value", fmt.Sprintf("failed to convert value to dynamic data: %v", err))
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *fileValueResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state fileValueResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.set(ctx, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = state.ID
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *fileValueResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data fileValueResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+
+	unlock, err := utils.LockFile(filename)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to lock file", err.Error())
+		return
+	}
+	defer unlock()
+
+	_, err = utils.UnsetValueFile(filename, data.Format.ValueString(), data.Path.ValueString(), r.providerData.decryptOptions())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError("Failed to remove value", fmt.Sprintf("failed to remove value at %s of %q: %v", data.Path.ValueString(), filename, err))
+	}
+}
+
+// set sets the value of the given model in its file, holding the lock of the file.
+func (r *fileValueResource) set(ctx context.Context, data fileValueResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	value, err := data.valueJSON(ctx)
+	if err != nil {
+		diags.AddAttributeError(path.Root("value"), "Failed to convert value", err.Error())
+		return diags
+	}
+
+	filename := data.Filename.ValueString()
+
+	unlock, err := utils.LockFile(filename)
+	if err != nil {
+		diags.AddError("Failed to lock file", err.Error())
+		return diags
+	}
+	defer unlock()
+
+	if _, err := utils.SetValueFile(filename, data.Format.ValueString(), data.Path.ValueString(), value, r.providerData.decryptOptions()); err != nil {
+		diags.AddError("Failed to set value", fmt.Sprintf("failed to set value at %s of %q: %v", data.Path.ValueString(), filename, err))
+	}
+
+	return diags
+}
+
+// valueJSON returns the value of the model as normalized JSON.
+func (m fileValueResourceModel) valueJSON(ctx context.Context) ([]byte, error) {
+	value, err := utils.DynamicToJSON(ctx, m.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert value to JSON: %w", err)
+	}
+
+	return utils.NormalizeJSON(value)
+}
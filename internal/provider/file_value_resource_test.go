@@ -0,0 +1,191 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// testCheckFileValue checks that the value at the given key path of the file at the given path
+// equals the given JSON value, or does not exist if want is empty.
+func testCheckFileValue(path string, format string, keyPath string, want string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		identity, err := os.ReadFile(fmt.Sprintf("%s/../../%s", wd, test_age_key_file))
+		if err != nil {
+			return err
+		}
+
+		keys, err := utils.NewKeys(string(identity))
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		got, found, err := utils.GetValue(data, format, keyPath, utils.DecryptOptions{Keys: keys})
+		if err != nil {
+			return err
+		}
+
+		if want == "" && found {
+			return fmt.Errorf("expected %s of %q to not exist, got %s", keyPath, path, got)
+		}
+
+		if want != "" && string(got) != want {
+			return fmt.Errorf("expected %s of %q to be %s, got %s", keyPath, path, want, got)
+		}
+
+		return nil
+	}
+}
+
+func TestFileValueResource_basic(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture, err := os.ReadFile(fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "secrets.sops.yaml")
+	if err := os.WriteFile(file, fixture, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := testHelperEncryptedFileProviderConfig(t)
+
+	// both resources change the same file concurrently
+	config := func(password string) string {
+		return provider + fmt.Sprintf(`
+resource "sops_file_value" "password" {
+	filename = %[1]q
+	path     = "[\"database\"][\"password\"]"
+	value    = %[2]q
+}
+
+resource "sops_file_value" "hosts" {
+	filename = %[1]q
+	path     = "/database/hosts"
+	value    = ["db-0", "db-1"]
+}
+`, file, password)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckFileValue(file, "yaml", "/database/password", ""),
+			testCheckFileValue(file, "yaml", "/database/hosts", ""),
+			testCheckFileValue(file, "yaml", "/abc", `"xyz"`),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: config("hunter2"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFileValue(file, "yaml", "/database/password", `"hunter2"`),
+					testCheckFileValue(file, "yaml", "/database/hosts", `["db-0","db-1"]`),
+					// other values are preserved
+					testCheckFileValue(file, "yaml", "/abc", `"xyz"`),
+					testCheckFileValue(file, "yaml", "/integers", `123`),
+				),
+			},
+			{
+				Config: config("hunter3"),
+				Check:  testCheckFileValue(file, "yaml", "/database/password", `"hunter3"`),
+			},
+			// a value changed outside of Terraform is set again
+			{
+				PreConfig: func() {
+					keys, err := utils.NewKeys(string(mustReadTestFile(t, test_age_key_file)))
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if _, err := utils.SetValueFile(file, "yaml", "/database/password", []byte(`"changed"`), utils.DecryptOptions{Keys: keys}); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config("hunter3"),
+				Check:  testCheckFileValue(file, "yaml", "/database/password", `"hunter3"`),
+			},
+		},
+	})
+}
+
+func TestFileValueResource_invalid(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperResourceConfig("sops_file_value", `
+	filename = "secrets.sops.yaml"
+	path     = "database.password"
+	value    = "hunter2"
+`),
+				ExpectError: regexp.MustCompile("invalid path"),
+			},
+			{
+				Config: testHelperResourceConfig("sops_file_value", `
+	filename = "secret.bin"
+	format   = "binary"
+	path     = "/data"
+	value    = "hunter2"
+`),
+				ExpectError: regexp.MustCompile("values cannot be set in binary files"),
+			},
+			{
+				Config: testHelperResourceConfig("sops_file_value", `
+	filename = "secrets.sops.env"
+	path     = "/PORT"
+	value    = 8080
+`),
+				ExpectError: regexp.MustCompile("only string values can be set in dotenv files"),
+			},
+			{
+				Config: testHelperResourceConfig("sops_file_value", `
+	filename = "secrets.sops.ini"
+	path     = "/database"
+	value    = { password = "hunter2" }
+`),
+				ExpectError: regexp.MustCompile("only string values can be set in ini files"),
+			},
+		},
+	})
+}
+
+// mustReadTestFile returns the content of the given file relative to the repository root.
+func mustReadTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(fmt.Sprintf("%s/../../%s", wd, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
@@ -0,0 +1,104 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that fileWithKeysFunction implements the Function interface.
+var _ function.Function = &fileWithKeysFunction{}
+
+type fileWithKeysFunction struct{}
+
+func NewFileWithKeysFunction() function.Function {
+	return &fileWithKeysFunction{}
+}
+
+func (f *fileWithKeysFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "file_with_keys"
+}
+
+func (f *fileWithKeysFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Reads and decrypts a sops encrypted file with the given key material.",
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Reads and decrypts a [sops](https://getsops.io/) encrypted file like the ` + utils.Code("file") + `
+			function, but with the given key material instead of the key sources sops discovers from the
+			environment. This allows to decrypt files owned by different teams in the same run, with
+			key material taken from other sources, e.g. ephemeral resources of a secret store. The key
+			material is an object, which supports the following attributes. All of them are optional.
+
+			- ` + utils.Code("age") + ` - A list of age identities, e.g. ` + utils.Code("AGE-SECRET-KEY-1...") + `.
+			  Each entry may contain multiple identities, one per line, like an age key file. Plugin
+			  identities run their plugin from the ` + utils.Code("PATH") + ` non-interactively, like on the provider.
+			- ` + utils.Code("pgp_armored") + ` - A list of ASCII armored PGP private keys. The keys are used
+			  directly, without GnuPG or its agent. Passphrase-protected keys are not supported.
+
+			Key types without key material, e.g. AWS KMS, are still looked up in the environment. An
+			optional format can be provided to specify the format of the encrypted file. If not
+			provided, we will try to infer the format from the file extension. Supported formats are ` +
+			utils.Code("yaml") + `, ` + utils.Code("json") + `, ` + utils.Code("dotenv") + `, ` +
+			utils.Code("ini") + `, and ` + utils.Code("binary") + `.
+
+			If the file format is any of the supported formats other than ` + utils.Code("binary") + `, the
+			decrypted data will also be returned as an object in the ` + utils.Code("data") + ` attribute.
+			Regardless of the format, the raw decrypted data will always be returned in the ` +
+			utils.Code("raw") + ` attribute.
+		`)),
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "file",
+				MarkdownDescription: "The path to the sops encrypted file.",
+			},
+			function.DynamicParameter{
+				Name:                "keys",
+				MarkdownDescription: "An object with the key material, e.g. `{ age = [\"AGE-SECRET-KEY-1...\"] }`.",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name:           "format",
+			Description:    "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. Optional.",
+			AllowNullValue: true,
+		},
+
+		Return: function.ObjectReturn{
+			AttributeTypes: sopsDecryptReturnAttrTypes,
+		},
+	}
+}
+
+func (f *fileWithKeysFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var file string
+	var keys types.Dynamic
+	var varargs []string
+
+	resp.Error = req.Arguments.Get(ctx, &file, &keys, &varargs)
+	if resp.Error != nil {
+		return
+	}
+
+	decryptReq := decryptRequest{
+		source:     file,
+		sourceType: sourceTypePath,
+	}
+	if len(varargs) > 0 {
+		decryptReq.format = varargs[0]
+	}
+
+	if err := parseKeysOption(keys.UnderlyingValue(), &decryptReq); err != nil {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("invalid keys: %v", err))
+		return
+	}
+
+	runDecryptRequest(ctx, decryptReq, resp)
+}
@@ -0,0 +1,133 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestFileWithKeysFunction(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// make sure no key material can be discovered from the environment
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("GNUPGHOME", t.TempDir())
+
+	basic := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	pgp := fmt.Sprintf("%s/../../%s", wd, fixture_pgp_yaml_file)
+
+	keys := fmt.Sprintf(`{
+		age         = [file("%s/../../%s")]
+		pgp_armored = [file("%s/../../%s")]
+	}`, wd, test_age_key_file, wd, test_pgp_key_file)
+
+	expected := knownvalue.ObjectExact(map[string]knownvalue.Check{
+		"raw": knownvalue.StringExact("abc: xyz\nintegers: 123\ntruthy: true\nfloats: 3.14e-10\n"),
+		"data": knownvalue.ObjectExact(map[string]knownvalue.Check{
+			"abc":      knownvalue.StringExact("xyz"),
+			"integers": knownvalue.Int64Exact(123),
+			"truthy":   knownvalue.Bool(true),
+			"floats":   knownvalue.Float64Exact(3.14e-10),
+		}),
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+output "test" {
+	value = %s(%q, %s)
+}
+`, functions["file_with_keys"], basic, keys),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", expected),
+				},
+			},
+			{
+				Config: fmt.Sprintf(`
+output "test" {
+	value = %s(%q, %s, "yaml")
+}
+`, functions["file_with_keys"], pgp, keys),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", expected),
+				},
+			},
+			// the decrypt function accepts the same key material
+			{
+				Config: testHelperDecryptFunctionConfig(fmt.Sprintf("%q", pgp), fmt.Sprintf(`{ keys = %s, extract = "/abc" }`, keys)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"raw": knownvalue.StringExact("xyz"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestFileWithKeysFunction_invalid(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("GNUPGHOME", t.TempDir())
+
+	pgp := fmt.Sprintf("%s/../../%s", wd, fixture_pgp_yaml_file)
+
+	config := func(keys string) string {
+		return fmt.Sprintf(`
+output "test" {
+	value = %s(%q, %s)
+}
+`, functions["file_with_keys"], pgp, keys)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config(`{ gpg = [] }`),
+				ExpectError: regexp.MustCompile("unsupported key type: gpg"),
+			},
+			{
+				Config:      config(`{ age = "AGE-SECRET-KEY-1..." }`),
+				ExpectError: regexp.MustCompile("age must be a list of strings"),
+			},
+			{
+				Config:      config(`{ pgp_armored = ["not a key"] }`),
+				ExpectError: regexp.MustCompile("failed to parse PGP key"),
+			},
+			// the age key does not match the PGP encrypted file
+			{
+				Config:      config(fmt.Sprintf(`{ age = [file("%s/../../%s")] }`, wd, test_age_key_file)),
+				ExpectError: regexp.MustCompile("failed to decrypt file"),
+			},
+		},
+	})
+}
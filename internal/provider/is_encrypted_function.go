@@ -0,0 +1,142 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+var sopsIsEncryptedReturnAttrTypes = map[string]attr.Type{
+	"encrypted": types.BoolType,
+	"format":    types.StringType,
+}
+
+// isEncryptedOptionParsers contains a parser for every supported attribute of the is_encrypted
+// options object.
+var isEncryptedOptionParsers = map[string]optionParser{
+	"source_type": parseSourceTypeOption,
+	"format":      parseFormatOption,
+}
+
+var _ function.Function = &isEncryptedFunction{}
+
+type isEncryptedFunction struct{}
+
+func NewIsEncryptedFunction() function.Function {
+	return &isEncryptedFunction{}
+}
+
+func (f *isEncryptedFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_encrypted"
+}
+
+func (f *isEncryptedFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Reports whether a file or string is a sops encrypted document.",
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Reports whether a file or string is a valid [sops](https://getsops.io/) encrypted document
+			without decrypting it. This allows to handle both plaintext and encrypted input with a
+			conditional expression. The behaviour is controlled by an options object, which supports
+			the following attributes. All of them are optional.
+
+			- ` + utils.Code("source_type") + ` - Either ` + utils.Code("path") + ` if the source is a path
+			  to a file, or ` + utils.Code("inline") + ` if the source is the data itself. Defaults to ` +
+			utils.Code("path") + `.
+			- ` + utils.Code("format") + ` - The format the data is checked against. Supported formats are ` +
+			utils.Code("yaml") + `, ` + utils.Code("json") + `, ` + utils.Code("dotenv") + `, ` +
+			utils.Code("ini") + `, and ` + utils.Code("binary") + `. Inferred from the file extension for
+			  paths if not provided. For inline data, all formats are tried if not provided.
+
+			The returned object contains whether the data is encrypted in the ` + utils.Code("encrypted") + `
+			attribute and the format that matched in the ` + utils.Code("format") + ` attribute, which is ` +
+			utils.Code("null") + ` if the data is not encrypted.
+			`)),
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "source",
+				MarkdownDescription: "The path to the file, or the data itself if `source_type` is `inline`.",
+			},
+			function.DynamicParameter{
+				Name:                "options",
+				MarkdownDescription: "An object with the options. May be `null` or `{}` to use the defaults.",
+				AllowNullValue:      true,
+			},
+		},
+
+		Return: function.ObjectReturn{
+			AttributeTypes: sopsIsEncryptedReturnAttrTypes,
+		},
+	}
+}
+
+func (f *isEncryptedFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var source string
+	var options types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &source, &options)
+	if resp.Error != nil {
+		return
+	}
+
+	isEncryptedReq := decryptRequest{
+		source:     source,
+		sourceType: sourceTypePath,
+	}
+
+	resp.Error = parseOptions(options, 1, isEncryptedOptionParsers, &isEncryptedReq)
+	if resp.Error != nil {
+		return
+	}
+
+	data := []byte(source)
+	format := isEncryptedReq.format
+
+	if isEncryptedReq.sourceType == sourceTypePath {
+		var err error
+		if data, err = os.ReadFile(source); err != nil {
+			resp.Error = function.NewFuncError(fmt.Sprintf("failed to read %q: %v", source, err))
+			return
+		}
+
+		// infer format from file extension if not explicitly provided
+		if format == "" {
+			format = utils.FileFormatFromPath(source)
+		}
+	}
+
+	if format != "" && !utils.IsValidFormat(format) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("invalid format: %s", format))
+		return
+	}
+
+	matchedFormat := types.StringNull()
+	matched, encrypted := utils.DetectEncryptedFormat(data, format)
+	if encrypted {
+		matchedFormat = types.StringValue(matched)
+	}
+
+	result, diags := types.ObjectValue(
+		sopsIsEncryptedReturnAttrTypes,
+		map[string]attr.Value{
+			"encrypted": types.BoolValue(encrypted),
+			"format":    matchedFormat,
+		},
+	)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, &result)
+}
@@ -0,0 +1,131 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestIsEncryptedFunction_path(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	plaintext := fmt.Sprintf("%s/plain.yaml", t.TempDir())
+	if err := os.WriteFile(plaintext, []byte("abc: xyz\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperOptionsFunctionConfig("is_encrypted", fmt.Sprintf("%q", fixture), "null"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"encrypted": knownvalue.Bool(true),
+							"format":    knownvalue.StringExact("yaml"),
+						}),
+					),
+				},
+			},
+			{
+				Config: testHelperOptionsFunctionConfig("is_encrypted", fmt.Sprintf("%q", plaintext), "null"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"encrypted": knownvalue.Bool(false),
+							"format":    knownvalue.Null(),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestIsEncryptedFunction_inline(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_sample_env_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperOptionsFunctionConfig("is_encrypted", fmt.Sprintf("file(%q)", fixture), `{ source_type = "inline" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"encrypted": knownvalue.Bool(true),
+							"format":    knownvalue.StringExact("dotenv"),
+						}),
+					),
+				},
+			},
+			{
+				Config: testHelperOptionsFunctionConfig("is_encrypted", `"abc: xyz"`, `{ source_type = "inline", format = "yaml" }`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"encrypted": knownvalue.Bool(false),
+							"format":    knownvalue.Null(),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestIsEncryptedFunction_invalid_format(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testHelperOptionsFunctionConfig("is_encrypted", fmt.Sprintf("%q", fixture), `{ format = "foobar" }`),
+				ExpectError: regexp.MustCompile("invalid format:.*"),
+			},
+			{
+				Config:      testHelperOptionsFunctionConfig("is_encrypted", fmt.Sprintf("%q", fixture), `{ ignore_mac = true }`),
+				ExpectError: regexp.MustCompile("unsupported option: ignore_mac"),
+			},
+		},
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+var sopsKeyPathAttrTypes = map[string]attr.Type{
+	"document":     types.Int64Type,
+	"path":         types.StringType,
+	"extract_path": types.StringType,
+	"type":         types.StringType,
+	"encrypted":    types.BoolType,
+}
+
+var _ function.Function = &keyPathsFunction{}
+
+type keyPathsFunction struct{}
+
+func NewKeyPathsFunction() function.Function {
+	return &keyPathsFunction{}
+}
+
+func (f *keyPathsFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "key_paths"
+}
+
+func (f *keyPathsFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Lists the key paths of a sops encrypted file without decrypting it.",
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Lists the paths of all values of a [sops](https://getsops.io/) encrypted file without
+			decrypting it. The keys of a sops document are stored in plaintext, so no key material is
+			needed. This allows to validate that required keys exist, e.g. in CI without access to any
+			of the keys the file is encrypted with.
+
+			An optional format can be provided to specify the format of the encrypted file. If not provided,
+			we will try to infer the format from the file extension. Supported formats are ` +
+			utils.Code("yaml") + `, ` + utils.Code("json") + `, ` + utils.Code("dotenv") + `, and ` +
+			utils.Code("ini") + `.
+
+			Every value is returned with its ` + utils.Code("path") + ` with the segments joined by dots,
+			e.g. ` + utils.Code("object_key.nested_list.0") + `, and its ` + utils.Code("extract_path") + `
+			in the syntax of the ` + utils.Code("extract") + ` function. ` + utils.Code("type") + ` is the
+			sops type tag, e.g. ` + utils.Code("str") + `, ` + utils.Code("int") + `, ` + utils.Code("float") + `,
+			or ` + utils.Code("bool") + `, and ` + utils.Code("encrypted") + ` is ` + utils.Code("false") + `
+			for values that are stored in plaintext, e.g. because they are covered by the ` +
+			utils.Code("unencrypted_suffix") + `. ` + utils.Code("document") + ` is the index of the
+			document, as YAML files can contain more than one.
+			`)),
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "file",
+				MarkdownDescription: "The path to the sops encrypted file.",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name:           "format",
+			Description:    "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, and `ini`. Optional.",
+			AllowNullValue: true,
+		},
+
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{AttrTypes: sopsKeyPathAttrTypes},
+		},
+	}
+}
+
+func (f *keyPathsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var file string
+	var varargs []string
+
+	resp.Error = req.Arguments.Get(ctx, &file, &varargs)
+	if resp.Error != nil {
+		return
+	}
+
+	// infer format from file extension if not explicitly provided
+	var format string
+	if len(varargs) > 0 {
+		format = varargs[0]
+	} else {
+		format = utils.FileFormatFromPath(file)
+	}
+
+	if !utils.IsValidFormat(format) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("invalid format: %s", format))
+		return
+	}
+
+	keyPaths, err := utils.ListKeyPathsFile(file, format)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("failed to list key paths: %v", err))
+		return
+	}
+
+	elements := make([]attr.Value, 0, len(keyPaths))
+	for _, keyPath := range keyPaths {
+		element, diags := types.ObjectValue(sopsKeyPathAttrTypes, map[string]attr.Value{
+			"document":     types.Int64Value(int64(keyPath.Document)),
+			"path":         types.StringValue(keyPath.Path),
+			"extract_path": types.StringValue(keyPath.ExtractPath),
+			"type":         types.StringValue(keyPath.Type),
+			"encrypted":    types.BoolValue(keyPath.Encrypted),
+		})
+		if diags.HasError() {
+			resp.Error = function.FuncErrorFromDiags(ctx, diags)
+			return
+		}
+
+		elements = append(elements, element)
+	}
+
+	result, diags := types.ListValue(types.ObjectType{AttrTypes: sopsKeyPathAttrTypes}, elements)
+	if diags.HasError() {
+		resp.Error = function.FuncErrorFromDiags(ctx, diags)
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, &result)
+}
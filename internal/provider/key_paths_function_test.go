@@ -0,0 +1,118 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func keyPathCheck(path, extractPath, valueType string, encrypted bool) knownvalue.Check {
+	return knownvalue.ObjectExact(map[string]knownvalue.Check{
+		"document":     knownvalue.Int64Exact(0),
+		"path":         knownvalue.StringExact(path),
+		"extract_path": knownvalue.StringExact(extractPath),
+		"type":         knownvalue.StringExact(valueType),
+		"encrypted":    knownvalue.Bool(encrypted),
+	})
+}
+
+func TestKeyPathsFunction_basic_yaml(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	// no key material must be needed to list the key paths
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperFunctionConfig("key_paths", fixture, ""),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ListExact([]knownvalue.Check{
+							keyPathCheck("abc", `["abc"]`, "str", true),
+							keyPathCheck("integers", `["integers"]`, "int", true),
+							keyPathCheck("truthy", `["truthy"]`, "bool", true),
+							keyPathCheck("floats", `["floats"]`, "float", true),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestKeyPathsFunction_complex_yaml(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_complex_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+output "test" {
+	value = [for k in %s(%q) : k.path if !k.encrypted]
+}
+`, functions["key_paths"], fixture),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.TupleExact([]knownvalue.Check{
+							knownvalue.StringExact("null_key"),
+							knownvalue.StringExact("list_key.4"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestKeyPathsFunction_binary(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_raw_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testHelperFunctionConfig("key_paths", fixture, ""),
+				ExpectError: regexp.MustCompile("not supported for binary data"),
+			},
+		},
+	})
+}
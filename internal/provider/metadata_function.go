@@ -0,0 +1,195 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+var sopsMasterKeyAttrTypes = map[string]attr.Type{
+	"type":       types.StringType,
+	"identifier": types.StringType,
+}
+
+var sopsMetadataReturnAttrTypes = map[string]attr.Type{
+	"lastmodified":              types.StringType,
+	"version":                   types.StringType,
+	"unencrypted_suffix":        types.StringType,
+	"encrypted_suffix":          types.StringType,
+	"unencrypted_regex":         types.StringType,
+	"encrypted_regex":           types.StringType,
+	"unencrypted_comment_regex": types.StringType,
+	"encrypted_comment_regex":   types.StringType,
+	"mac_only_encrypted":        types.BoolType,
+	"mac_present":               types.BoolType,
+	"shamir_threshold":          types.Int64Type,
+	"key_groups": types.ListType{
+		ElemType: types.ListType{
+			ElemType: types.ObjectType{AttrTypes: sopsMasterKeyAttrTypes},
+		},
+	},
+}
+
+var _ function.Function = &metadataFunction{}
+
+type metadataFunction struct{}
+
+func NewMetadataFunction() function.Function {
+	return &metadataFunction{}
+}
+
+func (f *metadataFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "metadata"
+}
+
+func (f *metadataFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Reads the metadata of a sops encrypted file without decrypting it.",
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Reads the metadata of a [sops](https://getsops.io/) encrypted file without decrypting it. No
+			key material is needed, so this function also works on machines without access to any of the
+			keys the file is encrypted with, e.g. in ` + utils.Code("check") + ` blocks or preconditions.
+
+			An optional format can be provided to specify the format of the encrypted file. If not provided,
+			we will try to infer the format from the file extension. Supported formats are ` +
+			utils.Code("yaml") + `, ` + utils.Code("json") + `, ` + utils.Code("dotenv") + `, ` +
+			utils.Code("ini") + `, and ` + utils.Code("binary") + `.
+
+			The returned object contains the ` + utils.Code("lastmodified") + ` timestamp, the sops ` +
+			utils.Code("version") + ` the file was written with, the settings controlling which values
+			are encrypted (` + utils.Code("null") + ` if not set), whether a MAC is present, and the key
+			groups with the type and identifier of each master key. The identifier is the recipient for
+			age keys, the fingerprint for PGP keys, the ARN for AWS KMS keys, and the key's resource ID
+			or URL for all other key types.
+			`)),
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "file",
+				MarkdownDescription: "The path to the sops encrypted file.",
+			},
+		},
+		VariadicParameter: function.StringParameter{
+			Name:           "format",
+			Description:    "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. Optional.",
+			AllowNullValue: true,
+		},
+
+		Return: function.ObjectReturn{
+			AttributeTypes: sopsMetadataReturnAttrTypes,
+		},
+	}
+}
+
+func (f *metadataFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var file string
+	var varargs []string
+
+	resp.Error = req.Arguments.Get(ctx, &file, &varargs)
+	if resp.Error != nil {
+		return
+	}
+
+	// infer format from file extension if not explicitly provided
+	var format string
+	if len(varargs) > 0 {
+		format = varargs[0]
+	} else {
+		format = utils.FileFormatFromPath(file)
+	}
+
+	if !utils.IsValidFormat(format) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("invalid format: %s", format))
+		return
+	}
+
+	metadata, err := utils.ReadMetadataFile(file, format)
+	if err != nil {
+		resp.Error = function.NewFuncError(fmt.Sprintf("failed to read metadata: %v", err))
+		return
+	}
+
+	result, funcErr := metadataObject(ctx, metadata)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, &result)
+}
+
+// metadataObject converts the given metadata into the object returned by the metadata function.
+func metadataObject(ctx context.Context, metadata utils.Metadata) (types.Object, *function.FuncError) {
+	keyGroupType := types.ListType{ElemType: types.ObjectType{AttrTypes: sopsMasterKeyAttrTypes}}
+
+	keyGroups := make([]attr.Value, 0, len(metadata.KeyGroups))
+	for _, group := range metadata.KeyGroups {
+		masterKeys := make([]attr.Value, 0, len(group))
+		for _, key := range group {
+			masterKey, diags := types.ObjectValue(sopsMasterKeyAttrTypes, map[string]attr.Value{
+				"type":       types.StringValue(key.Type),
+				"identifier": types.StringValue(key.Identifier),
+			})
+			if diags.HasError() {
+				return types.Object{}, function.FuncErrorFromDiags(ctx, diags)
+			}
+
+			masterKeys = append(masterKeys, masterKey)
+		}
+
+		keyGroup, diags := types.ListValue(keyGroupType.ElemType, masterKeys)
+		if diags.HasError() {
+			return types.Object{}, function.FuncErrorFromDiags(ctx, diags)
+		}
+
+		keyGroups = append(keyGroups, keyGroup)
+	}
+
+	keyGroupsValue, diags := types.ListValue(keyGroupType, keyGroups)
+	if diags.HasError() {
+		return types.Object{}, function.FuncErrorFromDiags(ctx, diags)
+	}
+
+	var lastModified types.String
+	if metadata.LastModified.IsZero() {
+		lastModified = types.StringNull()
+	} else {
+		lastModified = types.StringValue(metadata.LastModified.UTC().Format(time.RFC3339))
+	}
+
+	result, diags := types.ObjectValue(sopsMetadataReturnAttrTypes, map[string]attr.Value{
+		"lastmodified":              lastModified,
+		"version":                   optionalString(metadata.Version),
+		"unencrypted_suffix":        optionalString(metadata.UnencryptedSuffix),
+		"encrypted_suffix":          optionalString(metadata.EncryptedSuffix),
+		"unencrypted_regex":         optionalString(metadata.UnencryptedRegex),
+		"encrypted_regex":           optionalString(metadata.EncryptedRegex),
+		"unencrypted_comment_regex": optionalString(metadata.UnencryptedCommentRegex),
+		"encrypted_comment_regex":   optionalString(metadata.EncryptedCommentRegex),
+		"mac_only_encrypted":        types.BoolValue(metadata.MACOnlyEncrypted),
+		"mac_present":               types.BoolValue(metadata.MACPresent),
+		"shamir_threshold":          types.Int64Value(int64(metadata.ShamirThreshold)),
+		"key_groups":                keyGroupsValue,
+	})
+
+	return result, function.FuncErrorFromDiags(ctx, diags)
+}
+
+// optionalString returns a null string for empty values.
+func optionalString(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(value)
+}
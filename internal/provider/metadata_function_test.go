@@ -0,0 +1,118 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestMetadataFunction_basic_yaml(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	// no key material must be needed to read the metadata
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperFunctionConfig("metadata", fixture, ""),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"lastmodified":              knownvalue.StringExact("2024-11-27T20:58:06Z"),
+							"version":                   knownvalue.StringExact("3.9.1"),
+							"unencrypted_suffix":        knownvalue.StringExact("_unencrypted"),
+							"encrypted_suffix":          knownvalue.Null(),
+							"unencrypted_regex":         knownvalue.Null(),
+							"encrypted_regex":           knownvalue.Null(),
+							"unencrypted_comment_regex": knownvalue.Null(),
+							"encrypted_comment_regex":   knownvalue.Null(),
+							"mac_only_encrypted":        knownvalue.Bool(false),
+							"mac_present":               knownvalue.Bool(true),
+							"shamir_threshold":          knownvalue.Int64Exact(0),
+							"key_groups": knownvalue.ListExact([]knownvalue.Check{
+								knownvalue.ListExact([]knownvalue.Check{
+									knownvalue.ObjectExact(map[string]knownvalue.Check{
+										"type":       knownvalue.StringExact("age"),
+										"identifier": knownvalue.StringExact("age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn"),
+									}),
+								}),
+							}),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestMetadataFunction_sample_ini(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_sample_ini_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperFunctionConfig("metadata", fixture, "ini"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue(
+						"test",
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"mac_present": knownvalue.Bool(true),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestMetadataFunction_invalid_format(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.RequireAbove(version.Must(version.NewVersion("1.8.0"))),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testHelperFunctionConfig("metadata", fixture, "foobar"),
+				ExpectError: regexp.MustCompile("invalid format:.*"),
+			},
+		},
+	})
+}
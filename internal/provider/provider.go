@@ -5,13 +5,21 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/action"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/lithammer/dedent"
 	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
 )
@@ -19,6 +27,9 @@ import (
 // Ensure SopsProvider satisfies various provider interfaces.
 var _ provider.Provider = &SopsProvider{}
 var _ provider.ProviderWithFunctions = &SopsProvider{}
+var _ provider.ProviderWithEphemeralResources = &SopsProvider{}
+var _ provider.ProviderWithListResources = &SopsProvider{}
+var _ provider.ProviderWithActions = &SopsProvider{}
 
 // SopsProvider defines the provider implementation.
 type SopsProvider struct {
@@ -28,6 +39,17 @@ type SopsProvider struct {
 	version string
 }
 
+// SopsProviderModel describes the provider data model.
+type SopsProviderModel struct {
+	AgeIdentity                types.String `tfsdk:"age_identity"`
+	AgeIdentityFile            types.String `tfsdk:"age_identity_file"`
+	AgePluginSearchPath        types.List   `tfsdk:"age_plugin_search_path"`
+	AgePluginTimeout           types.String `tfsdk:"age_plugin_timeout"`
+	AgeSSHPrivateKey           types.String `tfsdk:"age_ssh_private_key"`
+	AgeSSHPrivateKeyFiles      types.List   `tfsdk:"age_ssh_private_key_files"`
+	AgeSSHPrivateKeyPassphrase types.String `tfsdk:"age_ssh_private_key_passphrase"`
+}
+
 func (p *SopsProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "sops"
 	resp.Version = p.version
@@ -47,30 +69,245 @@ func (p *SopsProvider) Schema(ctx context.Context, req provider.SchemaRequest, r
 			values. Marking a value as sensitive only redacts it from normal output; it does not prevent the
 			plaintext from being stored in state. Protect access to Terraform state accordingly.
 
+			If the decrypted data is only needed in ephemeral contexts, such as provider configuration blocks
+			or write-only attributes, use the ` + utils.Code("sops_file") + ` ephemeral resource instead. Its
+			results are never persisted in plan or state. Likewise, the ` + utils.Code("sops_secret_file") + `
+			resource encrypts ephemeral values through a write-only attribute without persisting the
+			plaintext.
+
 			Moreover, if the decrypted data is in one of the supported formats (` + utils.Code("yaml") + `, ` +
 			utils.Code("json") + `, ` + utils.Code("dotenv") + `, ` + utils.Code("ini") + `), it will also be
 			returned as a nested object in the ` + utils.Code("data") + ` attribute. This allows for easier
 			access to specific values within structured data.
+
+			By default, decryption uses the same key sources as sops, e.g. the ` + utils.Code("SOPS_AGE_KEY_FILE") + `
+			environment variable. Alternatively, age identities or SSH private keys, which sops can use as age
+			identities, can be configured on the provider. They are then used by the data sources, resources,
+			and ephemeral resources of this provider instead. Provider functions cannot access the provider
+			configuration and use the default key sources, unless key material is passed to them directly, see
+			the ` + utils.Code("file_with_keys") + ` function.
+
+			The key material is scoped to the provider instance it is configured on, so multiple provider
+			aliases with different identities can be used side by side without affecting each other.
 		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"age_identity": schema.StringAttribute{
+				MarkdownDescription: "One or more age identities, one per line, used to decrypt age encrypted files. Accepts ephemeral values, e.g. from an ephemeral resource of a secret store. Plugin identities, e.g. `AGE-PLUGIN-YUBIKEY-1...`, are supported, see `age_plugin_search_path`.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"age_identity_file": schema.StringAttribute{
+				MarkdownDescription: "The path to a file containing one or more age identities, one per line, used to decrypt age encrypted files. Can be combined with `age_identity`.",
+				Optional:            true,
+			},
+			"age_plugin_search_path": schema.ListAttribute{
+				MarkdownDescription: "The directories searched for the binaries of age plugins, e.g. `age-plugin-yubikey`, which are run to decrypt with plugin identities. Defaults to the directories of the `PATH` environment variable. Plugins are run non-interactively: if a plugin requests input, e.g. a PIN, or a confirmation, the request is refused and decryption fails instead of waiting for it.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"age_plugin_timeout": schema.StringAttribute{
+				MarkdownDescription: "The time an age plugin may take to decrypt a data key, e.g. while waiting for a hardware token to be touched, as a duration like `1m`. The plugin is stopped and decryption fails afterwards. Defaults to `30s`.",
+				Optional:            true,
+			},
+			"age_ssh_private_key": schema.StringAttribute{
+				MarkdownDescription: "An SSH ed25519 or RSA private key in the OpenSSH or PEM format, used as an age identity to decrypt files encrypted for its public key. Accepts ephemeral values, e.g. from an ephemeral resource of a secret store. Can be combined with the age identities.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"age_ssh_private_key_files": schema.ListAttribute{
+				MarkdownDescription: "The paths to SSH ed25519 or RSA private keys used as age identities, e.g. `pathexpand(\"~/.ssh/id_ed25519\")`. Unlike sops, the provider does not look up SSH keys in the home directory. Can be combined with `age_ssh_private_key`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"age_ssh_private_key_passphrase": schema.StringAttribute{
+				MarkdownDescription: "The passphrase of passphrase-protected SSH private keys, used for all configured keys that are passphrase-protected. Unencrypted keys ignore it. As the provider cannot prompt for a passphrase, configuring a passphrase-protected key without it is an error.",
+				Optional:            true,
+				Sensitive:           true,
+			},
+		},
 	}
 }
 
 func (p *SopsProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	// no configuration needed
+	var config SopsProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the configuration may not be known yet during validation or planning
+	if config.AgeIdentity.IsUnknown() || config.AgeIdentityFile.IsUnknown() ||
+		config.AgePluginSearchPath.IsUnknown() || config.AgePluginTimeout.IsUnknown() ||
+		config.AgeSSHPrivateKey.IsUnknown() || config.AgeSSHPrivateKeyFiles.IsUnknown() ||
+		config.AgeSSHPrivateKeyPassphrase.IsUnknown() {
+		configureUnknown(req, resp)
+		return
+	}
+
+	var identities []string
+
+	if identity := config.AgeIdentity.ValueString(); identity != "" {
+		identities = append(identities, identity)
+	}
+
+	if file := config.AgeIdentityFile.ValueString(); file != "" {
+		identity, err := os.ReadFile(file)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("age_identity_file"),
+				"Failed to read age identity file",
+				fmt.Sprintf("failed to read %q: %v", file, err),
+			)
+			return
+		}
+
+		identities = append(identities, string(identity))
+	}
+
+	var pluginOpts utils.AgePluginOptions
+
+	resp.Diagnostics.Append(config.AgePluginSearchPath.ElementsAs(ctx, &pluginOpts.SearchPath, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if timeout := config.AgePluginTimeout.ValueString(); timeout != "" {
+		var err error
+		if pluginOpts.Timeout, err = time.ParseDuration(timeout); err != nil || pluginOpts.Timeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("age_plugin_timeout"),
+				"Invalid age plugin timeout",
+				fmt.Sprintf("expected a positive duration like \"1m\", got %q", timeout),
+			)
+			return
+		}
+	}
+
+	var sshKeyFiles []types.String
+	resp.Diagnostics.Append(config.AgeSSHPrivateKeyFiles.ElementsAs(ctx, &sshKeyFiles, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	passphrase := config.AgeSSHPrivateKeyPassphrase.ValueString()
+
+	var sshKeys []utils.SSHKey
+
+	if key := config.AgeSSHPrivateKey.ValueString(); key != "" {
+		sshKeys = append(sshKeys, utils.SSHKey{PrivateKey: key, Passphrase: passphrase})
+	}
+
+	for i, file := range sshKeyFiles {
+		if file.IsUnknown() {
+			configureUnknown(req, resp)
+			return
+		}
+
+		key, err := os.ReadFile(file.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("age_ssh_private_key_files").AtListIndex(i),
+				"Failed to read SSH private key file",
+				fmt.Sprintf("failed to read %q: %v", file.ValueString(), err),
+			)
+			return
+		}
+
+		sshKeys = append(sshKeys, utils.SSHKey{PrivateKey: string(key), Passphrase: passphrase})
+	}
+
+	// key material is parsed once per provider instance, so that every alias of the provider only
+	// ever decrypts with its own identities
+	data := &sopsProviderData{}
+	if len(identities) > 0 || len(sshKeys) > 0 {
+		keys, err := utils.NewKeysFromMaterial(utils.KeyMaterial{Age: identities, AgePlugins: pluginOpts, SSH: sshKeys})
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to parse age identities", err.Error())
+			return
+		}
+
+		data.keys = keys
+	}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.EphemeralResourceData = data
+	resp.ListResourceData = data
+	resp.ActionData = data
+}
+
+// configureUnknown handles a provider configuration that is not known yet. Terraform is asked to
+// defer the operations of this provider if it supports it, otherwise the provider data is marked
+// as unknown, so that decrypting fails instead of silently using the key sources of the
+// environment.
+func configureUnknown(req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	if req.ClientCapabilities.DeferralAllowed {
+		resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+	}
+
+	data := &sopsProviderData{configUnknown: true}
+
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.EphemeralResourceData = data
+	resp.ListResourceData = data
+	resp.ActionData = data
 }
 
 func (p *SopsProvider) Resources(ctx context.Context) []func() resource.Resource {
-	return []func() resource.Resource{}
+	return []func() resource.Resource{
+		NewAgeKeyResource,
+		NewDataKeyRotationResource,
+		NewDecryptedFileResource,
+		NewEncryptedFileResource,
+		NewFileResource,
+		NewFileValueResource,
+		NewRekeyedFileResource,
+		NewSecretFileResource,
+	}
 }
 
 func (p *SopsProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewExternalDataSource,
+		NewFileDataSource,
+	}
+}
+
+func (p *SopsProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewAgeKeyEphemeralResource,
+		NewFileEphemeralResource,
+		NewStringEphemeralResource,
+		NewTempFileEphemeralResource,
+	}
+}
+
+func (p *SopsProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewFileListResource,
+	}
+}
+
+func (p *SopsProvider) Actions(ctx context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewUpdateKeysAction,
+	}
 }
 
 func (p *SopsProvider) Functions(ctx context.Context) []func() function.Function {
 	return []func() function.Function{
+		NewAgeRecipientFunction,
+		NewDecryptFunction,
+		NewExtractFunction,
 		NewFileFunction,
 		NewFileIgnoreMacFunction,
+		NewFileWithKeysFunction,
+		NewIsEncryptedFunction,
+		NewKeyPathsFunction,
+		NewMetadataFunction,
 		NewStringFunction,
 		NewStringIgnoreMacFunction,
 	}
@@ -0,0 +1,67 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// sopsProviderData is passed from the provider to its data sources, resources, and ephemeral
+// resources on configure.
+type sopsProviderData struct {
+	// keys contains the key material configured on this provider instance. It is nil if the
+	// provider uses the key sources from the environment.
+	keys *utils.Keys
+	// configUnknown is true if the provider configuration was not known on configure, e.g. because
+	// it depends on values that are only known after apply.
+	configUnknown bool
+}
+
+// errProviderConfigUnknown is returned when decrypting with a provider whose configuration is not
+// known yet.
+var errProviderConfigUnknown = errors.New("the provider configuration is not known yet, e.g. because it depends on values that are only known after apply; " +
+	"refusing to decrypt with the key sources of the environment instead of the configured key material")
+
+// decryptOptions returns the decrypt options for the configured key material. It is safe to call
+// on a nil receiver, in which case the default key sources of sops are used. If the provider
+// configuration is not known yet, every decryption with the returned options fails.
+func (d *sopsProviderData) decryptOptions() utils.DecryptOptions {
+	if d == nil {
+		return utils.DecryptOptions{}
+	}
+
+	if d.configUnknown {
+		return utils.DecryptOptions{
+			Keys: utils.NewUnavailableKeys(errProviderConfigUnknown),
+		}
+	}
+
+	return utils.DecryptOptions{
+		Keys: d.keys,
+	}
+}
+
+// providerDataFrom converts the provider data passed on configure, adding an error diagnostic if it
+// is of an unexpected type.
+func providerDataFrom(providerData any, diags *diag.Diagnostics) *sopsProviderData {
+	// the provider has not been configured yet
+	if providerData == nil {
+		return nil
+	}
+
+	data, ok := providerData.(*sopsProviderData)
+	if !ok {
+		diags.AddError(
+			"Unexpected Provider Data Type",
+			fmt.Sprintf("Expected *sopsProviderData, got: %T. Please report this issue to the provider developers.", providerData),
+		)
+		return nil
+	}
+
+	return data
+}
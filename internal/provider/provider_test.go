@@ -4,8 +4,23 @@
 package provider
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"filippo.io/age/plugin"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -16,8 +31,491 @@ var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServe
 	"sops": providerserver.NewProtocol6WithError(New("test")()),
 }
 
+// testAccProtoV6ProviderFactoriesWithEcho additionally includes the echo
+// provider, which is used to copy the results of ephemeral resources into
+// state so that they can be checked.
+var testAccProtoV6ProviderFactoriesWithEcho = map[string]func() (tfprotov6.ProviderServer, error){
+	"sops": providerserver.NewProtocol6WithError(New("test")()),
+	"echo": echoprovider.NewProviderServer(),
+}
+
 // func testAccPreCheck(t *testing.T) {
 // 	// You can add code here to run prior to any test case execution, for example assertions
 // 	// about the appropriate environment variables being set are common to see in a pre-check
 // 	// function.
 // }
+
+func TestProvider_age_identity_file(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_post_quantum_yaml_file)
+	key := fmt.Sprintf("%s/../../%s", wd, test_post_quantum_age_key_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "sops" {
+	age_identity_file = %q
+}
+`, key) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"message": knownvalue.StringExact("post-quantum works"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestProvider_age_identity(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_post_quantum_yaml_file)
+	key := fmt.Sprintf("%s/../../%s", wd, test_post_quantum_age_key_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "sops" {
+	age_identity = file(%q)
+}
+`, key) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"message": knownvalue.StringExact("post-quantum works"),
+						}),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestProvider_age_identity_replaces_environment(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the basic fixture can only be decrypted with the key from the environment
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	key := fmt.Sprintf("%s/../../%s", wd, test_post_quantum_age_key_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "sops" {
+	age_identity_file = %q
+}
+`, key) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ExpectError: regexp.MustCompile("failed to decrypt file"),
+			},
+		},
+	})
+}
+
+func TestProvider_unknown_configuration_does_not_use_environment(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fixture could be decrypted with the key from the environment, which must not be used
+	// while the identity of the provider is not known yet
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_post_quantum_yaml_file)
+	key := fmt.Sprintf("%s/../../%s", wd, test_post_quantum_age_key_file)
+	t.Setenv("SOPS_AGE_KEY_FILE", key)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "sops_age_key" "test" {}
+
+provider "sops" {
+	alias        = "pending"
+	age_identity = sops_age_key.test.identity
+}
+
+data "sops_file" "test" {
+	provider    = sops.pending
+	source_file = %q
+}
+`, fixture),
+				ExpectError: regexp.MustCompile("provider configuration is not known yet"),
+			},
+		},
+	})
+}
+
+func TestProvider_age_identity_file_missing(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "sops" {
+	age_identity_file = "does-not-exist.key"
+}
+` + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ExpectError: regexp.MustCompile("Failed to read age identity file"),
+			},
+		},
+	})
+}
+
+func TestProvider_age_ssh_private_key_files(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// make sure the keys cannot be discovered from the environment
+	t.Setenv("SOPS_AGE_SSH_PRIVATE_KEY_FILE", "")
+	t.Setenv("HOME", t.TempDir())
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_ssh_yaml_file)
+
+	config := func(key string) string {
+		return fmt.Sprintf(`
+provider "sops" {
+	age_ssh_private_key_files      = [%q]
+	age_ssh_private_key_passphrase = %q
+}
+`, fmt.Sprintf("%s/../../%s", wd, key), test_ssh_key_passphrase) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture))
+	}
+
+	check := []statecheck.StateCheck{
+		statecheck.ExpectKnownValue(
+			"echo.test",
+			tfjsonpath.New("data").AtMapKey("data").AtMapKey("abc"),
+			knownvalue.StringExact("xyz"),
+		),
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config:            config(test_ssh_ed25519_encrypted_key),
+				ConfigStateChecks: check,
+			},
+			{
+				Config:            config(test_ssh_rsa_encrypted_key),
+				ConfigStateChecks: check,
+			},
+			// the passphrase is ignored for unencrypted keys
+			{
+				Config:            config(test_ssh_ed25519_key_file),
+				ConfigStateChecks: check,
+			},
+		},
+	})
+}
+
+func TestProvider_age_ssh_private_key(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SOPS_AGE_SSH_PRIVATE_KEY_FILE", "")
+	t.Setenv("HOME", t.TempDir())
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_ssh_yaml_file)
+
+	key, err := os.ReadFile(fmt.Sprintf("%s/../../%s", wd, test_ssh_rsa_key_file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "sops" {
+	age_ssh_private_key = %q
+}
+`, key) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data").AtMapKey("abc"),
+						knownvalue.StringExact("xyz"),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestProvider_age_ssh_private_key_invalid(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_ssh_yaml_file)
+	key := fmt.Sprintf("%s/../../%s", wd, test_ssh_rsa_encrypted_key)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "sops" {
+	age_ssh_private_key_files = [%q]
+}
+`, key) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ExpectError: regexp.MustCompile("no passphrase was provided"),
+			},
+			{
+				Config: fmt.Sprintf(`
+provider "sops" {
+	age_ssh_private_key_files      = [%q]
+	age_ssh_private_key_passphrase = "hunter2"
+}
+`, key) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ExpectError: regexp.MustCompile("incorrect passphrase"),
+			},
+			{
+				Config: `
+provider "sops" {
+	age_ssh_private_key_files = ["does-not-exist.key"]
+}
+` + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture)),
+				ExpectError: regexp.MustCompile("Failed to read SSH private key file"),
+			},
+		},
+	})
+}
+
+func TestProvider_age_plugin(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake plugin wraps the age identity of the basic fixture
+	dir := t.TempDir()
+	out, err := exec.Command("go", "build", "-o", filepath.Join(dir, "age-plugin-fake"), "./utils/testdata/age-plugin-fake").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build error = %v: %s", err, out)
+	}
+
+	var identity string
+	for line := range strings.Lines(string(mustReadTestFile(t, test_age_key_file))) {
+		if strings.HasPrefix(line, "AGE-SECRET-KEY-1") {
+			identity = strings.TrimSpace(line)
+		}
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	config := func(mode string) string {
+		return fmt.Sprintf(`
+provider "sops" {
+	age_identity           = %q
+	age_plugin_search_path = [%q]
+	age_plugin_timeout     = "2s"
+}
+`, plugin.EncodeIdentity("fake", []byte(mode+":"+identity)), dir) + testHelperEphemeralConfig("sops_file", fmt.Sprintf(`file = %q`, fixture))
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: config("ok"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("data").AtMapKey("abc"),
+						knownvalue.StringExact("xyz"),
+					),
+				},
+			},
+			// the plugin requests a PIN, which is refused instead of waiting for it
+			{
+				Config:      config("pin"),
+				ExpectError: regexp.MustCompile(`interactive input,[\s|]+which[\s|]+is[\s|]+not[\s|]+supported`),
+			},
+			// the plugin waits for a hardware token to be touched until it is stopped
+			{
+				Config:      config("hang"),
+				ExpectError: regexp.MustCompile(`did[\s|]+not[\s|]+respond[\s|]+within[\s|]+2s`),
+			},
+		},
+	})
+}
+
+func TestProvider_age_plugin_timeout_invalid(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "sops" {
+	age_plugin_timeout = "soon"
+}
+` + testHelperEphemeralConfig("sops_file", `file = "secrets.sops.yaml"`),
+				ExpectError: regexp.MustCompile("Invalid age plugin timeout"),
+			},
+		},
+	})
+}
+
+func TestProvider_aliases_are_isolated(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	basic := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	postQuantum := fmt.Sprintf("%s/../../%s", wd, fixture_post_quantum_yaml_file)
+	key := fmt.Sprintf("%s/../../%s", wd, test_age_key_file)
+	postQuantumKey := fmt.Sprintf("%s/../../%s", wd, test_post_quantum_age_key_file)
+
+	// both aliases decrypt both fixtures in parallel, each only succeeding for the fixture
+	// encrypted with its own key
+	config := fmt.Sprintf(`
+provider "sops" {
+	alias             = "prod"
+	age_identity_file = %[1]q
+}
+
+provider "sops" {
+	alias             = "staging"
+	age_identity_file = %[2]q
+}
+
+ephemeral "sops_file" "prod" {
+	provider = sops.prod
+	file     = %[3]q
+}
+
+ephemeral "sops_file" "staging" {
+	provider = sops.staging
+	file     = %[4]q
+}
+
+provider "echo" {
+	data = {
+		prod    = ephemeral.sops_file.prod.data
+		staging = ephemeral.sops_file.staging.data
+	}
+}
+
+resource "echo" "test" {}
+`, key, postQuantumKey, basic, postQuantum)
+
+	swappedConfig := fmt.Sprintf(`
+provider "sops" {
+	alias             = "prod"
+	age_identity_file = %[1]q
+}
+
+provider "sops" {
+	alias             = "staging"
+	age_identity_file = %[2]q
+}
+
+ephemeral "sops_file" "prod" {
+	provider = sops.prod
+	file     = %[4]q
+}
+
+ephemeral "sops_file" "staging" {
+	provider = sops.staging
+	file     = %[3]q
+}
+`, key, postQuantumKey, basic, postQuantum)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("prod"),
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"abc": knownvalue.StringExact("xyz"),
+						}),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("staging"),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"message": knownvalue.StringExact("post-quantum works"),
+						}),
+					),
+				},
+			},
+			{
+				Config:      swappedConfig,
+				ExpectError: regexp.MustCompile("failed to decrypt file"),
+			},
+		},
+	})
+}
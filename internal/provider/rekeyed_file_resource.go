@@ -0,0 +1,343 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that rekeyedFileResource implements the Resource interfaces.
+var _ resource.Resource = &rekeyedFileResource{}
+var _ resource.ResourceWithConfigure = &rekeyedFileResource{}
+var _ resource.ResourceWithModifyPlan = &rekeyedFileResource{}
+var _ resource.ResourceWithValidateConfig = &rekeyedFileResource{}
+
+type rekeyedFileResource struct {
+	providerData *sopsProviderData
+}
+
+type rekeyedFileResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	Source          types.String `tfsdk:"source"`
+	SourceFormat    types.String `tfsdk:"source_format"`
+	Filename        types.String `tfsdk:"filename"`
+	Format          types.String `tfsdk:"format"`
+	KeyPaths        types.List   `tfsdk:"key_paths"`
+	Age             types.List   `tfsdk:"age"`
+	PGP             types.List   `tfsdk:"pgp"`
+	KMS             types.List   `tfsdk:"kms"`
+	KeyGroups       types.List   `tfsdk:"key_groups"`
+	ShamirThreshold types.Int64  `tfsdk:"shamir_threshold"`
+	SourceSHA256    types.String `tfsdk:"source_sha256"`
+	EncryptedSHA256 types.String `tfsdk:"encrypted_sha256"`
+	LastModified    types.String `tfsdk:"lastmodified"`
+}
+
+func NewRekeyedFileResource() resource.Resource {
+	return &rekeyedFileResource{}
+}
+
+func (r *rekeyedFileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rekeyed_file"
+}
+
+func (r *rekeyedFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			MarkdownDescription: "The path of the encrypted file.",
+			Computed:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"source": schema.StringAttribute{
+			MarkdownDescription: "The path to the sops encrypted source file.",
+			Required:            true,
+		},
+		"source_format": schema.StringAttribute{
+			MarkdownDescription: "The format of the source file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+			Optional:            true,
+			Computed:            true,
+		},
+		"filename": schema.StringAttribute{
+			MarkdownDescription: "The path of the encrypted file to write. Missing parent directories are created. Changing the path replaces the file.",
+			Required:            true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"format": schema.StringAttribute{
+			MarkdownDescription: "The format of the file to write. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+			Optional:            true,
+			Computed:            true,
+		},
+		"key_paths": schema.ListAttribute{
+			MarkdownDescription: "The paths of the values to copy from the source file, either in the sops `--extract` syntax, e.g. `[\"database\"][\"password\"]`, or as JSON Pointers, e.g. `/database/password`. Paths can only address object keys, arrays can only be copied as a whole. If not provided, all values are copied.",
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+		"key_groups": schema.ListNestedAttribute{
+			MarkdownDescription: "The key groups to encrypt the file for. With more than one key group, the data key is split between them and `shamir_threshold` of them are required to decrypt the file. Conflicts with `age`, `pgp`, and `kms`.",
+			Optional:            true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: recipientAttributes(""),
+			},
+		},
+		"shamir_threshold": schema.Int64Attribute{
+			MarkdownDescription: "The number of key groups required to decrypt the file. Defaults to all key groups.",
+			Optional:            true,
+		},
+		"source_sha256": schema.StringAttribute{
+			MarkdownDescription: "The SHA256 checksum of the source file the file was written from, as hex string.",
+			Computed:            true,
+		},
+		"encrypted_sha256": schema.StringAttribute{
+			MarkdownDescription: "The SHA256 checksum of the encrypted file, as hex string.",
+			Computed:            true,
+		},
+		"lastmodified": schema.StringAttribute{
+			MarkdownDescription: "The time the file was encrypted, as RFC3339 timestamp.",
+			Computed:            true,
+		},
+	}
+
+	// the recipients of a single key group can also be set directly on the resource
+	maps.Copy(attributes, recipientAttributes("Used as a single key group. Conflicts with `key_groups`."))
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Re-encrypts a [sops](https://getsops.io/) encrypted file for different recipients, e.g. to
+			hand a subset of secrets to a team with its own age keys. The source file is decrypted in
+			memory with the key material of the provider and written to a new file encrypted for the
+			given age recipients, PGP fingerprints, and AWS KMS keys, optionally limited to the values
+			at ` + utils.Code("key_paths") + `.
+
+			Neither the plaintext nor the ciphertext are stored in the state, only their checksums and
+			metadata. The file is written again if the source file changes, or if the file was modified
+			or deleted outside of Terraform.
+		`)),
+
+		Attributes: attributes,
+	}
+}
+
+func (r *rekeyedFileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *rekeyedFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data rekeyedFileResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.KeyGroups.IsNull() && (!data.Age.IsNull() || !data.PGP.IsNull() || !data.KMS.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("key_groups"),
+			"Conflicting recipients",
+			"key_groups cannot be combined with age, pgp, or kms",
+		)
+	}
+
+	for _, attribute := range []string{"format", "source_format"} {
+		var format types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(attribute), &format)...)
+
+		if format.ValueString() != "" && !utils.IsValidFormat(format.ValueString()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(attribute),
+				"Invalid format",
+				fmt.Sprintf("invalid format: %s", format.ValueString()),
+			)
+		}
+	}
+}
+
+func (r *rekeyedFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan rekeyedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// infer formats from file extensions if not explicitly provided, so that they are known during plan
+	if plan.Format.IsUnknown() && !plan.Filename.IsUnknown() {
+		format := utils.FileFormatFromPath(plan.Filename.ValueString())
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("format"), types.StringValue(format))...)
+	}
+
+	if plan.SourceFormat.IsUnknown() && !plan.Source.IsUnknown() {
+		format := utils.FileFormatFromPath(plan.Source.ValueString())
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("source_format"), types.StringValue(format))...)
+	}
+
+	// nothing else to do on create
+	if req.State.Raw.IsNull() || plan.Source.IsUnknown() {
+		return
+	}
+
+	// the file has to be written again if the source file changed since it was written
+	source, err := os.ReadFile(plan.Source.ValueString())
+	if err != nil || sha256Hex(source) == plan.SourceSHA256.ValueString() {
+		return
+	}
+
+	for _, attribute := range []string{"source_sha256", "encrypted_sha256", "lastmodified"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attribute), types.StringUnknown())...)
+	}
+}
+
+func (r *rekeyedFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data rekeyedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.rekey(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *rekeyedFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data rekeyedFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+
+	encrypted, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", fmt.Sprintf("failed to read %q: %v", filename, err))
+		return
+	}
+
+	// the provider usually cannot decrypt the file to compare its plaintext, as it is encrypted for
+	// other recipients, so any modification of the file causes it to be written again
+	if sha256Hex(encrypted) != data.EncryptedSHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *rekeyedFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data rekeyedFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.rekey(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *rekeyedFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data rekeyedFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(data.Filename.ValueString()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError("Failed to delete file", fmt.Sprintf("failed to delete %q: %v", data.Filename.ValueString(), err))
+	}
+}
+
+// rekey decrypts the source file of the given model, encrypts the selected values for the
+// recipients of the model and writes them to its file, updating the computed attributes of the
+// model.
+func (r *rekeyedFileResource) rekey(ctx context.Context, data *rekeyedFileResourceModel, diags *diag.Diagnostics) {
+	sourcePath := data.Source.ValueString()
+	sourceFormat := data.SourceFormat.ValueString()
+	filename := data.Filename.ValueString()
+	format := data.Format.ValueString()
+
+	var keyPaths []string
+	diags.Append(data.KeyPaths.ElementsAs(ctx, &keyPaths, false)...)
+
+	opts, d := recipientsEncryptOptions(ctx, keyGroupModel{Age: data.Age, PGP: data.PGP, KMS: data.KMS}, data.KeyGroups, data.ShamirThreshold)
+	diags.Append(d...)
+	if diags.HasError() {
+		return
+	}
+	opts.InputFormat = sourceFormat
+
+	source, err := os.ReadFile(sourcePath)
+	if err != nil {
+		diags.AddAttributeError(path.Root("source"), "Failed to read source file", fmt.Sprintf("failed to read %q: %v", sourcePath, err))
+		return
+	}
+
+	cleartext, err := utils.DecryptData(source, sourceFormat, r.providerData.decryptOptions())
+	if err != nil {
+		diags.AddError("Failed to decrypt source file", fmt.Sprintf("failed to decrypt %q: %v", sourcePath, err))
+		return
+	}
+
+	if len(keyPaths) > 0 {
+		cleartext, err = utils.SelectKeyPaths(cleartext, sourceFormat, keyPaths)
+		if err != nil {
+			diags.AddAttributeError(path.Root("key_paths"), "Failed to select key paths", fmt.Sprintf("failed to select key paths of %q: %v", sourcePath, err))
+			return
+		}
+	}
+
+	encrypted, err := utils.EncryptFile(filename, cleartext, format, opts)
+	if err != nil {
+		diags.AddError("Failed to encrypt file", fmt.Sprintf("failed to encrypt %q: %v", filename, err))
+		return
+	}
+
+	metadata, err := utils.ReadMetadata(encrypted, format)
+	if err != nil {
+		diags.AddError("Failed to read sops metadata", fmt.Sprintf("failed to read sops metadata of %q: %v", filename, err))
+		return
+	}
+
+	data.ID = types.StringValue(filename)
+	data.SourceSHA256 = types.StringValue(sha256Hex(source))
+	data.EncryptedSHA256 = types.StringValue(sha256Hex(encrypted))
+	data.LastModified = types.StringValue(metadata.LastModified.UTC().Format(time.RFC3339))
+}
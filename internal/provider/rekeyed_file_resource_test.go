@@ -0,0 +1,147 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/compare"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+func TestRekeyedFileResource_basic(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.sops.yaml")
+	file := filepath.Join(dir, "partner", "secret.sops.json")
+	provider := testHelperEncryptedFileProviderConfig(t)
+
+	opts := utils.EncryptOptions{KeyGroups: []utils.KeyGroup{{Age: []string{test_age_recipient}}}}
+	if _, err := utils.EncryptFile(source, []byte("database:\n    user: admin\n    password: hunter2\napi_token: secret\n"), "yaml", opts); err != nil {
+		t.Fatal(err)
+	}
+
+	sameChecksum := statecheck.CompareValue(compare.ValuesSame())
+
+	config := provider + testHelperResourceConfig("sops_rekeyed_file", fmt.Sprintf(`
+	source    = %q
+	filename  = %q
+	key_paths = ["[\"database\"][\"password\"]", "/api_token"]
+	age       = [%q]
+`, source, file, test_age_recipient))
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  testCheckEncryptedFile(file, "json", "{\n\t\"database\": {\n\t\t\"password\": \"hunter2\"\n\t},\n\t\"api_token\": \"secret\"\n}\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"sops_rekeyed_file.test",
+						tfjsonpath.New("format"),
+						knownvalue.StringExact("json"),
+					),
+					statecheck.ExpectKnownValue(
+						"sops_rekeyed_file.test",
+						tfjsonpath.New("source_format"),
+						knownvalue.StringExact("yaml"),
+					),
+					sameChecksum.AddStateValue(
+						"sops_rekeyed_file.test",
+						tfjsonpath.New("encrypted_sha256"),
+					),
+				},
+			},
+			// neither the source nor the file changed
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameChecksum.AddStateValue(
+						"sops_rekeyed_file.test",
+						tfjsonpath.New("encrypted_sha256"),
+					),
+				},
+			},
+			// the source changed, so the file is written again
+			{
+				PreConfig: func() {
+					if _, err := utils.EncryptFile(source, []byte("database:\n    password: changed\napi_token: secret\n"), "yaml", opts); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sops_rekeyed_file.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testCheckEncryptedFile(file, "json", "{\n\t\"database\": {\n\t\t\"password\": \"changed\"\n\t},\n\t\"api_token\": \"secret\"\n}\n"),
+			},
+			// the file was modified outside of Terraform, so it is written again
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(file, []byte("{}"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sops_rekeyed_file.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: testCheckEncryptedFile(file, "json", "{\n\t\"database\": {\n\t\t\"password\": \"changed\"\n\t},\n\t\"api_token\": \"secret\"\n}\n"),
+			},
+		},
+	})
+}
+
+func TestRekeyedFileResource_invalid(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	file := filepath.Join(t.TempDir(), "secret.sops.yaml")
+	provider := testHelperEncryptedFileProviderConfig(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testHelperResourceConfig("sops_rekeyed_file", fmt.Sprintf(`
+	source    = %q
+	filename  = %q
+	key_paths = ["/missing"]
+	age       = [%q]
+`, fixture, file, test_age_recipient)),
+				ExpectError: regexp.MustCompile("path not found: /missing"),
+			},
+			{
+				Config: provider + testHelperResourceConfig("sops_rekeyed_file", fmt.Sprintf(`
+	source = %q
+	filename = %q
+	age = [%q]
+	key_groups = [{ age = [%q] }]
+`, fixture, file, test_age_recipient, test_age_recipient)),
+				ExpectError: regexp.MustCompile("Conflicting recipients"),
+			},
+		},
+	})
+}
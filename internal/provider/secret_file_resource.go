@@ -0,0 +1,245 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that secretFileResource implements the Resource interfaces.
+var _ resource.Resource = &secretFileResource{}
+var _ resource.ResourceWithModifyPlan = &secretFileResource{}
+var _ resource.ResourceWithValidateConfig = &secretFileResource{}
+
+type secretFileResource struct{}
+
+type secretFileResourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	Filename         types.String `tfsdk:"filename"`
+	Format           types.String `tfsdk:"format"`
+	ContentWO        types.String `tfsdk:"content_wo"`
+	ContentWOVersion types.Int64  `tfsdk:"content_wo_version"`
+	Age              types.List   `tfsdk:"age"`
+	EncryptedSHA256  types.String `tfsdk:"encrypted_sha256"`
+}
+
+func NewSecretFileResource() resource.Resource {
+	return &secretFileResource{}
+}
+
+func (r *secretFileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_secret_file"
+}
+
+func (r *secretFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Writes a [sops](https://getsops.io/) encrypted file from a write-only attribute, e.g. to encrypt
+			a password of an ephemeral resource for the given age recipients. The plaintext is never
+			persisted in plan or state, only the path of the file and its metadata are.
+
+			As Terraform does not store the value of ` + utils.Code("content_wo") + `, it cannot detect
+			changes to it. The file is only encrypted again if ` + utils.Code("content_wo_version") + `,
+			the format, or the recipients change, so increment ` + utils.Code("content_wo_version") + `
+			whenever the content should be updated. A file that was deleted outside of Terraform is
+			written again.
+
+			Write-only attributes are available in Terraform v1.11 and later.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The path of the encrypted file.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"filename": schema.StringAttribute{
+				MarkdownDescription: "The path of the encrypted file to write. Missing parent directories are created. Changing the path replaces the file.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "The format of the file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"content_wo": schema.StringAttribute{
+				MarkdownDescription: "The plaintext content to encrypt, in the format of the file. This value is write-only and never stored in plan or state.",
+				Required:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+			},
+			"content_wo_version": schema.Int64Attribute{
+				MarkdownDescription: "A version of `content_wo`. Changing it encrypts the file again with the current value of `content_wo`.",
+				Optional:            true,
+			},
+			"age": schema.ListAttribute{
+				MarkdownDescription: "The age recipients to encrypt the file for, e.g. `age1...`.",
+				ElementType:         types.StringType,
+				Required:            true,
+			},
+			"encrypted_sha256": schema.StringAttribute{
+				MarkdownDescription: "The SHA256 checksum of the encrypted file, as hex string.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *secretFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data secretFileResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if format := data.Format.ValueString(); format != "" && !utils.IsValidFormat(format) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+	}
+}
+
+func (r *secretFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan secretFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// infer format from file extension if not explicitly provided, so that it is known during plan
+	if plan.Format.IsUnknown() && !plan.Filename.IsUnknown() {
+		format := utils.FileFormatFromPath(plan.Filename.ValueString())
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("format"), types.StringValue(format))...)
+	}
+}
+
+func (r *secretFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data secretFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.encrypt(ctx, req.Config, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *secretFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data secretFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+
+	// the plaintext is not known, so only a deleted file can be detected
+	if _, err := os.Stat(filename); errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *secretFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state secretFileResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the value of content_wo is not stored, so only a new version, format or recipients require
+	// encrypting the file again
+	if data.ContentWOVersion.Equal(state.ContentWOVersion) && data.Format.Equal(state.Format) && data.Age.Equal(state.Age) {
+		data.ID = state.ID
+		data.EncryptedSHA256 = state.EncryptedSHA256
+	} else {
+		r.encrypt(ctx, req.Config, &data, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *secretFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data secretFileResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := os.Remove(data.Filename.ValueString()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError("Failed to delete file", fmt.Sprintf("failed to delete %q: %v", data.Filename.ValueString(), err))
+	}
+}
+
+// encrypt encrypts the write-only content of the given config and writes it to the file of the
+// given model, updating the computed attributes of the model.
+func (r *secretFileResource) encrypt(ctx context.Context, config tfsdk.Config, data *secretFileResourceModel, diags *diag.Diagnostics) {
+	// write-only values are only available in the config
+	var content types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("content_wo"), &content)...)
+	if diags.HasError() {
+		return
+	}
+
+	var recipients []string
+	diags.Append(data.Age.ElementsAs(ctx, &recipients, false)...)
+	if diags.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+
+	encrypted, err := utils.EncryptFile(filename, []byte(content.ValueString()), data.Format.ValueString(), utils.EncryptOptions{
+		KeyGroups: []utils.KeyGroup{{Age: recipients}},
+	})
+	if err != nil {
+		diags.AddError("Failed to encrypt file", fmt.Sprintf("failed to encrypt %q: %v", filename, err))
+		return
+	}
+
+	data.ID = types.StringValue(filename)
+	data.ContentWO = types.StringNull()
+	data.EncryptedSHA256 = types.StringValue(sha256Hex(encrypted))
+}
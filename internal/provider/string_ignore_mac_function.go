@@ -5,21 +5,13 @@ package provider
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/function"
-	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/lithammer/dedent"
 	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
 )
 
-var sopsStringIgnoreMacReturnAttrTypes = map[string]attr.Type{
-	"raw":  types.StringType,
-	"data": types.DynamicType,
-}
-
 var _ function.Function = &stringIgnoreMacFunction{}
 
 type stringIgnoreMacFunction struct{}
@@ -64,7 +56,7 @@ func (f *stringIgnoreMacFunction) Definition(ctx context.Context, req function.D
 		},
 
 		Return: function.ObjectReturn{
-			AttributeTypes: sopsStringIgnoreMacReturnAttrTypes,
+			AttributeTypes: sopsDecryptReturnAttrTypes,
 		},
 	}
 }
@@ -78,51 +70,14 @@ func (f *stringIgnoreMacFunction) Run(ctx context.Context, req function.RunReque
 		return
 	}
 
-	// infer format from file extension if not explicitly provided
-	format := ""
-	if len(varargs) > 0 {
-		format = varargs[0]
-	} else {
-		format = "binary"
-	}
-
-	if !utils.IsValidFormat(format) {
-		resp.Error = function.NewFuncError(fmt.Sprintf("invalid format: %s", format))
-		return
+	decryptReq := decryptRequest{
+		source:     data,
+		sourceType: sourceTypeInline,
+		ignoreMAC:  true,
 	}
-
-	// decrypt sops file
-	databytes := []byte(data)
-	cleartext, err := utils.DecryptData(databytes, format, utils.DecryptOptions{IgnoreMACMismatch: true})
-	if err != nil {
-		resp.Error = function.NewFuncError(fmt.Sprintf("failed to decrypt file: %v", err))
-		return
-	}
-
-	json, err := utils.UnmarshalDecryptedData(cleartext, format)
-	if err != nil {
-		resp.Error = function.NewFuncError(fmt.Sprintf("failed to unmarshal decrypted data: %v", err))
-		return
-	}
-
-	dynamicData, err := utils.JSONToDynamicImplied(json)
-	if err != nil {
-		resp.Error = function.NewFuncError(fmt.Sprintf("failed to convert decrypted data to dynamic data: %v", err))
-		return
-	}
-
-	result, diags := types.ObjectValue(
-		sopsStringIgnoreMacReturnAttrTypes,
-		map[string]attr.Value{
-			"raw":  types.StringValue(string(cleartext)),
-			"data": dynamicData,
-		},
-	)
-
-	resp.Error = function.FuncErrorFromDiags(ctx, diags)
-	if resp.Error != nil {
-		return
+	if len(varargs) > 0 {
+		decryptReq.format = varargs[0]
 	}
 
-	resp.Error = resp.Result.Set(ctx, &result)
+	runDecryptRequest(ctx, decryptReq, resp)
 }
@@ -0,0 +1,216 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// tempFilePrivateKey is the private data key the path of the temporary file is stored under, so
+// that it can be removed again on close.
+const tempFilePrivateKey = "path"
+
+// Ensure that tempFileEphemeralResource implements the EphemeralResource interfaces.
+var _ ephemeral.EphemeralResourceWithClose = &tempFileEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &tempFileEphemeralResource{}
+
+type tempFileEphemeralResource struct {
+	providerData *sopsProviderData
+}
+
+type tempFileEphemeralResourceModel struct {
+	File         types.String `tfsdk:"file"`
+	Format       types.String `tfsdk:"format"`
+	OutputFormat types.String `tfsdk:"output_format"`
+	IgnoreMac    types.Bool   `tfsdk:"ignore_mac"`
+	Directory    types.String `tfsdk:"directory"`
+	Path         types.String `tfsdk:"path"`
+}
+
+type tempFilePrivateData struct {
+	Path string `json:"path"`
+}
+
+func NewTempFileEphemeralResource() ephemeral.EphemeralResource {
+	return &tempFileEphemeralResource{}
+}
+
+func (r *tempFileEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_temp_file"
+}
+
+func (r *tempFileEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Decrypts a [sops](https://getsops.io/) encrypted file into a private temporary file and returns
+			its path. This is useful for tools and provider arguments that expect a file path rather than
+			the file contents, such as kubeconfigs, service account keys, or client certificates.
+
+			The temporary file is created with ` + utils.Code("0600") + ` permissions and is removed again
+			once Terraform closes the ephemeral resource at the end of the run. Neither the decrypted data
+			nor the path is persisted in plan or state.
+
+			An optional format can be provided to specify the format of the encrypted file. If not provided,
+			we will try to infer the format from the file extension. The decrypted data can optionally be
+			converted to a different format using ` + utils.Code("output_format") + `. Supported formats are ` +
+			utils.Code("yaml") + `, ` + utils.Code("json") + `, ` + utils.Code("dotenv") + `, ` +
+			utils.Code("ini") + `, and ` + utils.Code("binary") + `.
+
+			Ephemeral resources are available in Terraform v1.10 and later.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"file": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops encrypted file.",
+				Required:            true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "The format of the encrypted file. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the file extension.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"output_format": schema.StringAttribute{
+				MarkdownDescription: "The format the decrypted data is written in. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. Defaults to `format`.",
+				Optional:            true,
+				Computed:            true,
+			},
+			"ignore_mac": schema.BoolAttribute{
+				MarkdownDescription: "Whether to ignore a MAC mismatch when decrypting the file. Defaults to `false`.",
+				Optional:            true,
+			},
+			"directory": schema.StringAttribute{
+				MarkdownDescription: "The directory the temporary file is created in. Defaults to the system temporary directory.",
+				Optional:            true,
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "The path to the decrypted temporary file.",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (r *tempFileEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	r.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (r *tempFileEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data tempFileEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	file := data.File.ValueString()
+
+	// infer format from file extension if not explicitly provided
+	format := data.Format.ValueString()
+	if format == "" {
+		format = utils.FileFormatFromPath(file)
+	}
+
+	if !utils.IsValidFormat(format) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+		return
+	}
+
+	// keep the input format if no output format is provided
+	outputFormat := data.OutputFormat.ValueString()
+	if outputFormat == "" {
+		outputFormat = format
+	}
+
+	if !utils.IsValidFormat(outputFormat) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("output_format"),
+			"Invalid output format",
+			fmt.Sprintf("invalid output format: %s", outputFormat),
+		)
+		return
+	}
+
+	opts := r.providerData.decryptOptions()
+	opts.IgnoreMACMismatch = data.IgnoreMac.ValueBool()
+	opts.OutputFormat = outputFormat
+
+	// decrypt sops file
+	cleartext, err := utils.DecryptFile(file, format, opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decrypt file", fmt.Sprintf("failed to decrypt file: %v", err))
+		return
+	}
+
+	// os.CreateTemp creates the file with 0600 permissions
+	tempFile, err := os.CreateTemp(data.Directory.ValueString(), "sops-*"+utils.FileExtensionForFormat(outputFormat))
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create temporary file", fmt.Sprintf("failed to create temporary file: %v", err))
+		return
+	}
+
+	_, err = tempFile.Write(cleartext)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tempFile.Name())
+		resp.Diagnostics.AddError("Failed to write temporary file", fmt.Sprintf("failed to write temporary file: %v", err))
+		return
+	}
+
+	privateData, err := json.Marshal(tempFilePrivateData{Path: tempFile.Name()})
+	if err != nil {
+		_ = os.Remove(tempFile.Name())
+		resp.Diagnostics.AddError("Failed to store private data", fmt.Sprintf("failed to marshal private data: %v", err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, tempFilePrivateKey, privateData)...)
+	if resp.Diagnostics.HasError() {
+		_ = os.Remove(tempFile.Name())
+		return
+	}
+
+	data.Format = types.StringValue(format)
+	data.OutputFormat = types.StringValue(outputFormat)
+	data.Path = types.StringValue(tempFile.Name())
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+func (r *tempFileEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	privateData, diags := req.Private.GetKey(ctx, tempFilePrivateKey)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || privateData == nil {
+		return
+	}
+
+	var data tempFilePrivateData
+	if err := json.Unmarshal(privateData, &data); err != nil {
+		resp.Diagnostics.AddError("Failed to read private data", fmt.Sprintf("failed to unmarshal private data: %v", err))
+		return
+	}
+
+	// the file may already have been removed by someone else, which is fine
+	if err := os.Remove(data.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddError("Failed to remove temporary file", fmt.Sprintf("failed to remove %q: %v", data.Path, err))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+// expectTempFileRemoved checks that the temporary file whose path was echoed
+// into the state of echo.test no longer exists once the run has finished.
+type expectTempFileRemoved struct{}
+
+func (e expectTempFileRemoved) CheckState(ctx context.Context, req statecheck.CheckStateRequest, resp *statecheck.CheckStateResponse) {
+	if req.State == nil || req.State.Values == nil || req.State.Values.RootModule == nil {
+		resp.Error = errors.New("state is empty")
+		return
+	}
+
+	for _, r := range req.State.Values.RootModule.Resources {
+		if r.Address != "echo.test" {
+			continue
+		}
+
+		data, ok := r.AttributeValues["data"].(map[string]any)
+		if !ok {
+			resp.Error = fmt.Errorf("unexpected data attribute: %v", r.AttributeValues["data"])
+			return
+		}
+
+		path, ok := data["path"].(string)
+		if !ok || path == "" {
+			resp.Error = fmt.Errorf("unexpected path attribute: %v", data["path"])
+			return
+		}
+
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			resp.Error = fmt.Errorf("expected temporary file %q to be removed, got: %v", path, err)
+		}
+
+		return
+	}
+
+	resp.Error = errors.New("echo.test not found in state")
+}
+
+func TestTempFileEphemeralResource_basic_yaml(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_temp_file", fmt.Sprintf(`file = %q`, fixture)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("format"),
+						knownvalue.StringExact("yaml"),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("output_format"),
+						knownvalue.StringExact("yaml"),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("path"),
+						knownvalue.StringRegexp(regexp.MustCompile(`sops-[0-9]+\.yaml$`)),
+					),
+					expectTempFileRemoved{},
+				},
+			},
+		},
+	})
+}
+
+func TestTempFileEphemeralResource_output_format(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+	directory := t.TempDir()
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_temp_file", fmt.Sprintf(`
+	file          = %q
+	output_format = "json"
+	directory     = %q
+`, fixture, directory)),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("output_format"),
+						knownvalue.StringExact("json"),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("path"),
+						knownvalue.StringRegexp(regexp.MustCompile(`^`+regexp.QuoteMeta(directory)+`/sops-[0-9]+\.json$`)),
+					),
+					expectTempFileRemoved{},
+				},
+			},
+		},
+	})
+}
+
+func TestTempFileEphemeralResource_basic_mac_mismatch(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_mac_mismatch_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_temp_file", fmt.Sprintf(`file = %q`, fixture)),
+				ExpectError: regexp.MustCompile(
+					".*failed to verify data integrity.*",
+				),
+			},
+		},
+	})
+}
+
+func TestTempFileEphemeralResource_invalid_output_format(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := fmt.Sprintf("%s/../../%s", wd, fixture_basic_yaml_file)
+
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactoriesWithEcho,
+		Steps: []resource.TestStep{
+			{
+				Config: testHelperEphemeralConfig("sops_temp_file", fmt.Sprintf(`
+	file          = %q
+	output_format = "foobar"
+`, fixture)),
+				ExpectError: regexp.MustCompile("invalid output format:.*"),
+			},
+		},
+	})
+}
@@ -15,13 +15,29 @@ const (
 	fixture_sample_ini_file         = "test/fixtures/sample.sops.ini"
 	fixture_sample_env_file         = "test/fixtures/dot.sops.env"
 	fixture_basic_mac_mismatch_file = "test/fixtures/basic-mac-mismatch.sops.yaml"
+	fixture_pgp_yaml_file           = "test/fixtures/pgp.sops.yaml"
+	fixture_ssh_yaml_file           = "test/fixtures/ssh.sops.yaml"
+	test_age_key_file               = "test/age.key"
 	test_post_quantum_age_key_file  = "test/age-pq.key"
+	test_ssh_ed25519_key_file       = "test/ssh-ed25519.key"
+	test_ssh_ed25519_encrypted_key  = "test/ssh-ed25519-encrypted.key"
+	test_ssh_rsa_key_file           = "test/ssh-rsa.key"
+	test_ssh_rsa_encrypted_key      = "test/ssh-rsa-encrypted.key"
+	test_ssh_key_passphrase         = "correct horse battery staple"
+	test_pgp_key_file               = "test/pgp.key"
 )
 
 var (
 	functions = map[string]string{
+		"age_recipient":     "provider::sops::age_recipient",
+		"decrypt":           "provider::sops::decrypt",
+		"extract":           "provider::sops::extract",
 		"file":              "provider::sops::file",
 		"file_ignore_mac":   "provider::sops::file_ignore_mac",
+		"file_with_keys":    "provider::sops::file_with_keys",
+		"is_encrypted":      "provider::sops::is_encrypted",
+		"key_paths":         "provider::sops::key_paths",
+		"metadata":          "provider::sops::metadata",
 		"string":            "provider::sops::string",
 		"string_ignore_mac": "provider::sops::string_ignore_mac",
 	}
@@ -33,7 +49,7 @@ func testHelperFunctionConfig(fn string, file string, format string) string {
 	}
 
 	switch fn {
-	case "file", "file_ignore_mac":
+	case "file", "file_ignore_mac", "key_paths", "metadata":
 		return fmt.Sprintf(
 			`
 output "test" {
@@ -42,7 +58,7 @@ output "test" {
 `,
 			functions[fn], file, format,
 		)
-	case "string", "string_ignore_mac":
+	case "age_recipient", "string", "string_ignore_mac":
 		return fmt.Sprintf(
 			`
 output "test" {
@@ -55,3 +71,57 @@ output "test" {
 
 	return ""
 }
+
+func testHelperDecryptFunctionConfig(source string, options string) string {
+	return testHelperOptionsFunctionConfig("decrypt", source, options)
+}
+
+func testHelperOptionsFunctionConfig(fn string, source string, options string) string {
+	return fmt.Sprintf(
+		`
+output "test" {
+	value = %s(%s, %s)
+}
+`,
+		functions[fn], source, options,
+	)
+}
+
+func testHelperEphemeralConfig(resource string, attributes string) string {
+	return fmt.Sprintf(
+		`
+ephemeral "%s" "test" {
+%s
+}
+
+provider "echo" {
+	data = ephemeral.%s.test
+}
+
+resource "echo" "test" {}
+`,
+		resource, attributes, resource,
+	)
+}
+
+func testHelperDataSourceConfig(dataSource string, attributes string) string {
+	return fmt.Sprintf(
+		`
+data "%s" "test" {
+%s
+}
+`,
+		dataSource, attributes,
+	)
+}
+
+func testHelperResourceConfig(resource string, attributes string) string {
+	return fmt.Sprintf(
+		`
+resource "%s" "test" {
+%s
+}
+`,
+		resource, attributes,
+	)
+}
@@ -0,0 +1,196 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/lithammer/dedent"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+// Ensure that updateKeysAction implements the Action interfaces.
+var _ action.Action = &updateKeysAction{}
+var _ action.ActionWithConfigure = &updateKeysAction{}
+var _ action.ActionWithValidateConfig = &updateKeysAction{}
+
+type updateKeysAction struct {
+	providerData *sopsProviderData
+}
+
+type updateKeysActionModel struct {
+	Path       types.String `tfsdk:"path"`
+	ConfigFile types.String `tfsdk:"config_file"`
+	Format     types.String `tfsdk:"format"`
+}
+
+func NewUpdateKeysAction() action.Action {
+	return &updateKeysAction{}
+}
+
+func (a *updateKeysAction) Metadata(ctx context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_update_keys"
+}
+
+func (a *updateKeysAction) Schema(ctx context.Context, req action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: strings.TrimSpace(dedent.Dedent(`
+			Updates the key groups of existing [sops](https://getsops.io/) encrypted files to match the
+			creation rules of a sops config file, like ` + utils.Code("sops updatekeys") + `. The data key
+			of each file is decrypted with the key material of the provider and encrypted again for the
+			master keys of the matching creation rule, so neither the data key nor the plaintext of the
+			files change. Files whose key groups are already up to date are left untouched.
+
+			The files that changed, and the master keys added to or removed from them, are reported as
+			progress messages of the action.
+		`)),
+
+		Attributes: map[string]schema.Attribute{
+			"path": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops encrypted file to update, or a glob matching multiple files, e.g. `secrets/**/*.sops.yaml`. Globs support `*`, `?`, character classes, and `**` to match any number of directories. Files matched by a glob that are not sops encrypted, files larger than 16 MiB, and files in `.git` directories are skipped.",
+				Required:            true,
+			},
+			"config_file": schema.StringAttribute{
+				MarkdownDescription: "The path to the sops config file to resolve the creation rules from. If not provided, the `.sops.yaml` file in the directory of each file or its closest parent directory is used, like sops does.",
+				Optional:            true,
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "The format of the files. Supported formats are `yaml`, `json`, `dotenv`, `ini`, and `binary`. If not provided, the format is inferred from the extension of each file.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (a *updateKeysAction) Configure(ctx context.Context, req action.ConfigureRequest, resp *action.ConfigureResponse) {
+	a.providerData = providerDataFrom(req.ProviderData, &resp.Diagnostics)
+}
+
+func (a *updateKeysAction) ValidateConfig(ctx context.Context, req action.ValidateConfigRequest, resp *action.ValidateConfigResponse) {
+	var data updateKeysActionModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if format := data.Format.ValueString(); format != "" && !utils.IsValidFormat(format) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid format",
+			fmt.Sprintf("invalid format: %s", format),
+		)
+	}
+}
+
+func (a *updateKeysAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data updateKeysActionModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pattern := data.Path.ValueString()
+
+	files, warnings, err := matchingFiles(pattern)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"Failed to find encrypted files",
+			fmt.Sprintf("failed to find encrypted files matching %q: %v", pattern, err),
+		)
+		return
+	}
+
+	for _, warning := range warnings {
+		resp.Diagnostics.AddAttributeWarning(path.Root("path"), "Skipped unreadable file", warning.Error())
+	}
+
+	if len(files) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			"No encrypted files found",
+			fmt.Sprintf("no sops encrypted files match %q", pattern),
+		)
+		return
+	}
+
+	changed := 0
+	for _, file := range files {
+		format := data.Format.ValueString()
+		if format == "" {
+			format = file.Format
+		}
+
+		// files are updated independently, so that a single failing file does not prevent the
+		// others from being updated
+		result, err := utils.UpdateKeysFile(file.Path, format, data.ConfigFile.ValueString(), a.providerData.decryptOptions())
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to update keys", fmt.Sprintf("failed to update keys of %q: %v", file.Path, err))
+			continue
+		}
+
+		if !result.Changed {
+			resp.SendProgress(action.InvokeProgressEvent{
+				Message: fmt.Sprintf("%s: already up to date", file.Path),
+			})
+			continue
+		}
+
+		changed++
+		resp.SendProgress(action.InvokeProgressEvent{
+			Message: fmt.Sprintf("%s: updated keys%s", file.Path, describeKeyChanges(result)),
+		})
+	}
+
+	resp.SendProgress(action.InvokeProgressEvent{
+		Message: fmt.Sprintf("updated keys of %d of %d files", changed, len(files)),
+	})
+}
+
+// matchingFiles returns the sops encrypted files matching the given path or glob. A path without
+// wildcards is returned as is, so that errors reading it are reported when it is updated. Entries
+// that cannot be read while searching for matches are returned as warnings.
+func matchingFiles(pattern string) ([]utils.EncryptedFile, []error, error) {
+	dir, rest := utils.SplitGlob(pattern)
+	if rest == "" {
+		return []utils.EncryptedFile{{Path: pattern, Format: utils.FileFormatFromPath(pattern)}}, nil, nil
+	}
+
+	return utils.FindEncryptedFiles(dir, []string{rest}, nil)
+}
+
+// describeKeyChanges returns a description of the master keys added and removed by the given
+// update, e.g. ", added age age1..., removed pgp 85D7...".
+func describeKeyChanges(result utils.UpdateKeysResult) string {
+	var b strings.Builder
+
+	for _, change := range []struct {
+		verb string
+		keys []utils.MasterKey
+	}{
+		{verb: "added", keys: result.Added},
+		{verb: "removed", keys: result.Removed},
+	} {
+		if len(change.keys) == 0 {
+			continue
+		}
+
+		descriptions := make([]string, 0, len(change.keys))
+		for _, key := range change.keys {
+			descriptions = append(descriptions, key.Type+" "+key.Identifier)
+		}
+
+		fmt.Fprintf(&b, ", %s %s", change.verb, strings.Join(descriptions, ", "))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,123 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/nobbs/terraform-provider-sops/internal/provider/utils"
+)
+
+const test_other_age_recipient = "age1y6ca38htznet3puh3vcwfqmymhxqtlnrkf5hygt8lxpsnfx5zuzq5wsrfz"
+
+// testCheckRecipients checks that the file at the given path is encrypted for exactly the given
+// age recipients.
+func testCheckRecipients(path string, format string, want ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		metadata, err := utils.ReadMetadataFile(path, format)
+		if err != nil {
+			return err
+		}
+
+		var got []string
+		for _, group := range metadata.KeyGroups {
+			for _, key := range group {
+				got = append(got, key.Identifier)
+			}
+		}
+
+		if !slices.Equal(got, want) {
+			return fmt.Errorf("expected %q to be encrypted for %q, got %q", path, want, got)
+		}
+
+		return nil
+	}
+}
+
+func testHelperUpdateKeysActionConfig(path string) string {
+	return fmt.Sprintf(`
+action "sops_update_keys" "test" {
+	config {
+		path = %q
+	}
+}
+
+resource "terraform_data" "test" {
+	lifecycle {
+		action_trigger {
+			events  = [after_create]
+			actions = [action.sops_update_keys.test]
+		}
+	}
+}
+`, path)
+}
+
+func TestUpdateKeysAction_glob(t *testing.T) {
+	dir := t.TempDir()
+	provider := testHelperEncryptedFileProviderConfig(t)
+
+	// the JSON document is valid YAML as well
+	opts := utils.EncryptOptions{KeyGroups: []utils.KeyGroup{{Age: []string{test_age_recipient}}}}
+	for _, name := range []string{"dev/secret.sops.yaml", "prod/secret.sops.yaml", "prod/secret.sops.json"} {
+		if _, err := utils.EncryptFile(filepath.Join(dir, name), []byte(`{"password": "hunter2"}`), utils.FileFormatFromPath(name), opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := fmt.Sprintf(`
+creation_rules:
+  - path_regex: ^prod/
+    age: %s,%s
+  - age: %s
+`, test_age_recipient, test_other_age_recipient, test_age_recipient)
+
+	if err := os.WriteFile(filepath.Join(dir, ".sops.yaml"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: provider + testHelperUpdateKeysActionConfig(dir+"/**/*.sops.yaml"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRecipients(filepath.Join(dir, "prod/secret.sops.yaml"), "yaml", test_age_recipient, test_other_age_recipient),
+					testCheckRecipients(filepath.Join(dir, "dev/secret.sops.yaml"), "yaml", test_age_recipient),
+					// not matched by the glob
+					testCheckRecipients(filepath.Join(dir, "prod/secret.sops.json"), "json", test_age_recipient),
+					testCheckEncryptedFile(filepath.Join(dir, "prod/secret.sops.yaml"), "yaml", "password: hunter2\n"),
+				),
+			},
+		},
+	})
+}
+
+func TestUpdateKeysAction_noMatch(t *testing.T) {
+	dir := t.TempDir()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      testHelperUpdateKeysActionConfig(dir + "/*.sops.yaml"),
+				ExpectError: regexp.MustCompile("no sops encrypted files match"),
+			},
+		},
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"golang.org/x/crypto/ssh"
+)
+
+// AgeKey is an age key pair.
+type AgeKey struct {
+	// Identity is the secret identity, e.g. AGE-SECRET-KEY-1...
+	Identity string
+	// Recipient is the public recipient of the identity, e.g. age1...
+	Recipient string
+}
+
+// GenerateAgeKey generates a new age key pair, either a classic X25519 key pair or a post-quantum
+// hybrid ML-KEM-768 + X25519 key pair, like age-keygen and age-keygen -pq.
+func GenerateAgeKey(postQuantum bool) (AgeKey, error) {
+	if postQuantum {
+		identity, err := age.GenerateHybridIdentity()
+		if err != nil {
+			return AgeKey{}, err
+		}
+
+		return AgeKey{Identity: identity.String(), Recipient: identity.Recipient().String()}, nil
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return AgeKey{}, err
+	}
+
+	return AgeKey{Identity: identity.String(), Recipient: identity.Recipient().String()}, nil
+}
+
+// AgeRecipient returns the recipient of the given age identity, like age-keygen -y. The identity is
+// either an X25519 or post-quantum hybrid identity, optionally in the format of an age key file
+// with comments, or an unencrypted or passphrase-protected SSH ed25519 or RSA private key. The
+// recipient of an SSH key is its public key in the authorized_keys format, without comment.
+func AgeRecipient(identity string) (string, error) {
+	identity = strings.TrimSpace(identity)
+
+	if strings.HasPrefix(identity, "-----BEGIN") {
+		return sshAgeRecipient([]byte(identity))
+	}
+
+	var lines []string
+	for line := range strings.Lines(identity) {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) != 1 {
+		return "", fmt.Errorf("expected exactly one age identity, got %d", len(lines))
+	}
+
+	switch line := lines[0]; {
+	case strings.HasPrefix(line, "AGE-SECRET-KEY-PQ-1"):
+		parsed, err := age.ParseHybridIdentity(line)
+		if err != nil {
+			return "", err
+		}
+
+		return parsed.Recipient().String(), nil
+	case strings.HasPrefix(line, "AGE-SECRET-KEY-1"):
+		parsed, err := age.ParseX25519Identity(line)
+		if err != nil {
+			return "", err
+		}
+
+		return parsed.Recipient().String(), nil
+	default:
+		return "", errors.New("unsupported identity: expected an age identity starting with AGE-SECRET-KEY- or an SSH private key")
+	}
+}
+
+// sshAgeRecipient returns the public key of the given SSH private key in the authorized_keys
+// format. The public key of passphrase-protected keys is read without decrypting them.
+func sshAgeRecipient(pemBytes []byte) (string, error) {
+	var publicKey ssh.PublicKey
+
+	key, err := ssh.ParseRawPrivateKey(pemBytes)
+	if missing := (*ssh.PassphraseMissingError)(nil); errors.As(err, &missing) {
+		if missing.PublicKey == nil {
+			return "", errors.New("failed to read the public key of the passphrase-protected SSH key, convert it to the OpenSSH format")
+		}
+
+		publicKey = missing.PublicKey
+	} else if err != nil {
+		return "", fmt.Errorf("failed to parse SSH private key: %w", err)
+	} else {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return "", fmt.Errorf("unsupported SSH key type %T, only ed25519 and RSA keys are supported", key)
+		}
+
+		if publicKey, err = ssh.NewPublicKey(signer.Public()); err != nil {
+			return "", fmt.Errorf("unsupported SSH key: %w", err)
+		}
+	}
+
+	if publicKey.Type() != ssh.KeyAlgoED25519 && publicKey.Type() != ssh.KeyAlgoRSA {
+		return "", fmt.Errorf("unsupported SSH key type %s, only ed25519 and RSA keys are supported", publicKey.Type())
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))), nil
+}
+
+// sshAgeIdentity returns the age identity of the given SSH private key. Passphrase-protected keys
+// are decrypted right away, so that a missing or wrong passphrase is reported early instead of on
+// the first decryption.
+func sshAgeIdentity(key SSHKey) (age.Identity, error) {
+	pemBytes := []byte(strings.TrimSpace(key.PrivateKey))
+
+	parsed, err := ssh.ParseRawPrivateKey(pemBytes)
+	if missing := (*ssh.PassphraseMissingError)(nil); errors.As(err, &missing) {
+		if key.Passphrase == "" {
+			return nil, errors.New("the SSH private key is passphrase-protected, but no passphrase was provided")
+		}
+
+		parsed, err = ssh.ParseRawPrivateKeyWithPassphrase(pemBytes, []byte(key.Passphrase))
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, errors.New("incorrect passphrase for the SSH private key")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH private key: %w", err)
+	}
+
+	switch k := parsed.(type) {
+	case *ed25519.PrivateKey:
+		return agessh.NewEd25519Identity(*k)
+	case ed25519.PrivateKey:
+		return agessh.NewEd25519Identity(k)
+	case *rsa.PrivateKey:
+		return agessh.NewRSAIdentity(k)
+	default:
+		return nil, fmt.Errorf("unsupported SSH key type %T, only ed25519 and RSA keys are supported", parsed)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/plugin"
+)
+
+// DefaultAgePluginTimeout is the time an age plugin may take to unwrap a data key if
+// AgePluginOptions.Timeout is not set.
+const DefaultAgePluginTimeout = 30 * time.Second
+
+// AgePluginOptions configures how age plugins, e.g. age-plugin-yubikey, are run to decrypt data
+// keys with plugin identities (AGE-PLUGIN-...). Plugins are always run non-interactively: requests
+// for input, e.g. a PIN, and for confirmations are refused, so that decryption fails instead of
+// waiting for input that can never be provided.
+type AgePluginOptions struct {
+	// SearchPath contains the directories searched for the age-plugin-<name> binaries, in order. If
+	// empty, the directories of the PATH environment variable are searched.
+	SearchPath []string
+	// Timeout is the time a plugin may take to unwrap a data key, e.g. while waiting for a hardware
+	// token to be touched, before it is stopped. If zero, DefaultAgePluginTimeout is used.
+	Timeout time.Duration
+}
+
+// agePluginIdentity is an age plugin identity that unwraps file keys by running the plugin binary
+// with the identity-v1 state machine of the age plugin protocol, see https://c2sp.org/age-plugin.
+// Unlike the plugin client of age, which sops uses, it looks up the binary in a configurable search
+// path, refuses interactive requests, and stops the plugin after a timeout.
+type agePluginIdentity struct {
+	// name is the name of the plugin, e.g. yubikey for age-plugin-yubikey.
+	name string
+	// encoding is the identity as passed to the plugin, e.g. AGE-PLUGIN-YUBIKEY-1...
+	encoding string
+	opts     AgePluginOptions
+}
+
+var _ age.Identity = &agePluginIdentity{}
+
+// newAgePluginIdentity parses the given plugin identity. The plugin binary is only looked up when
+// the identity is used, so that missing plugins do not prevent decrypting other files.
+func newAgePluginIdentity(identity string, opts AgePluginOptions) (*agePluginIdentity, error) {
+	name, _, err := plugin.ParseIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agePluginIdentity{name: name, encoding: identity, opts: opts}, nil
+}
+
+// isAgePluginIdentity reports whether the given line of an age identity file is a plugin
+// identity.
+func isAgePluginIdentity(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "AGE-PLUGIN-")
+}
+
+// Unwrap runs the plugin to unwrap the file key from the given stanzas.
+func (i *agePluginIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	fileKey, err := i.unwrap(stanzas)
+	if err != nil && !errors.Is(err, age.ErrIncorrectIdentity) {
+		return nil, fmt.Errorf("age plugin %s: %w", i.name, err)
+	}
+
+	return fileKey, err
+}
+
+func (i *agePluginIdentity) unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	binary, err := i.lookPath()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cmp.Or(i.opts.Timeout, DefaultAgePluginTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stderr limitedBuffer
+	stderr.limit = agePluginStderrLimit
+
+	cmd := exec.CommandContext(ctx, binary, "--age-plugin=identity-v1")
+	// like age, do not let plugins rely on the working directory
+	cmd.Dir = os.TempDir()
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", binary, err)
+	}
+
+	fileKey, err := i.exchange(stdin, bufio.NewReader(stdout), stanzas)
+
+	// the standard error of the plugin is complete once it has exited
+	_ = stdin.Close()
+	_ = cmd.Wait()
+
+	if err == nil || errors.Is(err, age.ErrIncorrectIdentity) {
+		return fileKey, err
+	}
+
+	if ctx.Err() != nil {
+		err = fmt.Errorf("the plugin did not respond within %s, it may be waiting for a hardware token to be touched or for interactive input, which is not supported", timeout)
+	}
+
+	// the standard error usually explains why a plugin failed, e.g. during startup
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		err = fmt.Errorf("%w, plugin output: %s", err, msg)
+	}
+
+	return nil, err
+}
+
+// agePluginStderrLimit is the number of bytes of the standard error of a plugin that are added to
+// its errors.
+const agePluginStderrLimit = 4 << 10
+
+// limitedBuffer is a buffer that keeps the first limit bytes written to it and discards the rest.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+// Write writes the given bytes to the buffer as long as it is not full. It never fails, so that
+// a plugin writing more output is not stopped.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if n := b.limit - b.buf.Len(); n > 0 {
+		b.buf.Write(p[:min(n, len(p))])
+	}
+
+	return len(p), nil
+}
+
+// String returns the kept bytes.
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// exchange sends the identity and the stanzas to the plugin and handles its responses until it
+// is done.
+func (i *agePluginIdentity) exchange(w io.Writer, r *bufio.Reader, stanzas []*age.Stanza) ([]byte, error) {
+	// phase 1: the identity and the stanzas of a single file are sent to the plugin
+	if err := writeAgePluginStanza(w, "add-identity", []string{i.encoding}, nil); err != nil {
+		return nil, err
+	}
+
+	for _, s := range stanzas {
+		if err := writeAgePluginStanza(w, "recipient-stanza", append([]string{"0", s.Type}, s.Args...), s.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeAgePluginStanza(w, "done", nil, nil); err != nil {
+		return nil, err
+	}
+
+	// phase 2: the plugin responds with commands until it is done
+	var fileKey []byte
+	var refused []string
+
+	for {
+		s, err := readAgePluginStanza(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch s.Type {
+		case "file-key":
+			if len(s.Args) != 1 || s.Args[0] != "0" || fileKey != nil {
+				return nil, fmt.Errorf("malformed file-key stanza from plugin: %v", s.Args)
+			}
+
+			fileKey = s.Body
+			err = writeAgePluginStanza(w, "ok", nil, nil)
+		case "error":
+			if err := writeAgePluginStanza(w, "ok", nil, nil); err != nil {
+				return nil, err
+			}
+
+			return nil, refusedError(errors.New(string(s.Body)), refused)
+		case "msg":
+			// there is nobody to display the message to, but it does not require a response
+			err = writeAgePluginStanza(w, "ok", nil, nil)
+		case "request-secret", "request-public", "confirm":
+			refused = append(refused, string(s.Body))
+			err = writeAgePluginStanza(w, "fail", nil, nil)
+		case "done":
+			if fileKey != nil {
+				return fileKey, nil
+			}
+
+			if len(refused) > 0 {
+				return nil, refusedError(errors.New("no file key was unwrapped"), refused)
+			}
+
+			return nil, age.ErrIncorrectIdentity
+		default:
+			err = writeAgePluginStanza(w, "unsupported", nil, nil)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// lookPath returns the absolute path of the plugin binary.
+func (i *agePluginIdentity) lookPath() (string, error) {
+	binary := "age-plugin-" + i.name
+
+	if len(i.opts.SearchPath) == 0 {
+		path, err := exec.LookPath(binary)
+		if err != nil {
+			return "", fmt.Errorf("%s not found in PATH: %w", binary, err)
+		}
+
+		return filepath.Abs(path)
+	}
+
+	for _, dir := range i.opts.SearchPath {
+		if path, err := exec.LookPath(filepath.Join(dir, binary)); err == nil {
+			return filepath.Abs(path)
+		}
+	}
+
+	return "", fmt.Errorf("%s not found in the plugin search path %s", binary, strings.Join(i.opts.SearchPath, string(os.PathListSeparator)))
+}
+
+// refusedError adds the refused interactive requests of a plugin to the given error, as they are
+// usually the reason why the plugin failed.
+func refusedError(err error, refused []string) error {
+	if len(refused) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%w, the plugin requested interactive input, which is not supported: %q", err, refused)
+}
+
+// agePluginStanzaColumns is the number of base64 characters per line of a stanza body.
+const agePluginStanzaColumns = 64
+
+// writeAgePluginStanza writes a stanza in the age format to the plugin.
+func writeAgePluginStanza(w io.Writer, typ string, args []string, body []byte) error {
+	var b strings.Builder
+
+	b.WriteString("-> " + typ)
+	for _, arg := range args {
+		b.WriteString(" " + arg)
+	}
+	b.WriteString("\n")
+
+	// the body is wrapped, its last line is always shorter than a full line and may be empty
+	encoded := base64.RawStdEncoding.EncodeToString(body)
+	for len(encoded) >= agePluginStanzaColumns {
+		b.WriteString(encoded[:agePluginStanzaColumns] + "\n")
+		encoded = encoded[agePluginStanzaColumns:]
+	}
+	b.WriteString(encoded + "\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write to plugin: %w", err)
+	}
+
+	return nil
+}
+
+// readAgePluginStanza reads a stanza in the age format from the plugin.
+func readAgePluginStanza(r *bufio.Reader) (*age.Stanza, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from plugin: %w", err)
+	}
+
+	fields := strings.Split(strings.TrimSuffix(line, "\n"), " ")
+	if len(fields) < 2 || fields[0] != "->" {
+		return nil, fmt.Errorf("malformed stanza from plugin: %q", line)
+	}
+
+	var encoded strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from plugin: %w", err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) > agePluginStanzaColumns {
+			return nil, fmt.Errorf("malformed stanza body from plugin: %q", line)
+		}
+
+		encoded.WriteString(line)
+		if len(line) < agePluginStanzaColumns {
+			break
+		}
+	}
+
+	body, err := base64.RawStdEncoding.Strict().DecodeString(encoded.String())
+	if err != nil {
+		return nil, fmt.Errorf("malformed stanza body from plugin: %w", err)
+	}
+
+	return &age.Stanza{Type: fields[1], Args: fields[2:], Body: body}, nil
+}
@@ -0,0 +1,215 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age/plugin"
+)
+
+// buildFakeAgePlugin builds the fake age plugin in testdata/age-plugin-fake and returns the
+// directory containing its binary.
+func buildFakeAgePlugin(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	out, err := exec.Command("go", "build", "-o", filepath.Join(dir, "age-plugin-fake"), "./testdata/age-plugin-fake").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build error = %v: %s", err, out)
+	}
+
+	return dir
+}
+
+// fakeAgePluginIdentity returns an identity of the fake age plugin with the given mode, wrapping
+// the age identity of the basic fixtures.
+func fakeAgePluginIdentity(t *testing.T, mode string) string {
+	t.Helper()
+
+	var identity string
+	for line := range strings.Lines(string(mustReadFile(t, testAgeKeyFile))) {
+		if strings.HasPrefix(line, "AGE-SECRET-KEY-1") {
+			identity = strings.TrimSpace(line)
+		}
+	}
+
+	return plugin.EncodeIdentity("fake", []byte(mode+":"+identity))
+}
+
+func TestDecryptFileWithAgePlugin(t *testing.T) {
+	dir := buildFakeAgePlugin(t)
+
+	// make sure neither the plugin nor any identities can be discovered from the environment
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name    string
+		mode    string
+		opts    AgePluginOptions
+		path    string
+		wantErr string
+	}{
+		{
+			name: "search path",
+			mode: "ok",
+			opts: AgePluginOptions{SearchPath: []string{t.TempDir(), dir}},
+		},
+		{
+			name: "PATH",
+			mode: "ok",
+			path: dir,
+		},
+		{
+			name:    "not found",
+			mode:    "ok",
+			opts:    AgePluginOptions{SearchPath: []string{t.TempDir()}},
+			wantErr: "age-plugin-fake not found in the plugin search path",
+		},
+		{
+			name:    "not in PATH",
+			mode:    "ok",
+			wantErr: "age-plugin-fake not found in PATH",
+		},
+		{
+			name:    "PIN is refused",
+			mode:    "pin",
+			opts:    AgePluginOptions{SearchPath: []string{dir}},
+			wantErr: `the plugin requested interactive input, which is not supported: ["Enter PIN for fake token"]`,
+		},
+		{
+			name:    "plugin output",
+			mode:    "crash",
+			opts:    AgePluginOptions{SearchPath: []string{dir}},
+			wantErr: "plugin output: fake token not connected",
+		},
+		{
+			name:    "timeout",
+			mode:    "hang",
+			opts:    AgePluginOptions{SearchPath: []string{dir}, Timeout: 500 * time.Millisecond},
+			wantErr: "the plugin did not respond within 500ms",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.path != "" {
+				t.Setenv("PATH", test.path)
+			}
+
+			keys, err := NewKeysFromMaterial(KeyMaterial{
+				Age:        []string{"# fake token\n" + fakeAgePluginIdentity(t, test.mode) + "\n"},
+				AgePlugins: test.opts,
+			})
+			if err != nil {
+				t.Fatalf("NewKeysFromMaterial() error = %v", err)
+			}
+
+			cleartext, err := DecryptFile(fixtureBasicYAMLFile, "yaml", DecryptOptions{Keys: keys})
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(unwrapUserError(err), test.wantErr) {
+					t.Fatalf("DecryptFile() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DecryptFile() error = %v", err)
+			}
+
+			if want := "abc: xyz\nintegers: 123\ntruthy: true\nfloats: 3.14e-10\n"; string(cleartext) != want {
+				t.Errorf("DecryptFile() = %q, want %q", cleartext, want)
+			}
+		})
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	t.Parallel()
+
+	b := limitedBuffer{limit: 4}
+
+	for _, p := range []string{"ab", "cde", "f"} {
+		if n, err := b.Write([]byte(p)); n != len(p) || err != nil {
+			t.Fatalf("Write(%q) = (%d, %v), want (%d, nil)", p, n, err, len(p))
+		}
+	}
+
+	if got := b.String(); got != "abcd" {
+		t.Errorf("String() = %q, want %q", got, "abcd")
+	}
+}
+
+func TestDecryptFileWithAgePluginAndNativeIdentities(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	// the native identity is tried first, so the missing plugin is never run
+	keys, err := NewKeysFromMaterial(KeyMaterial{
+		Age: []string{fakeAgePluginIdentity(t, "ok"), string(mustReadFile(t, testAgeKeyFile))},
+	})
+	if err != nil {
+		t.Fatalf("NewKeysFromMaterial() error = %v", err)
+	}
+
+	if _, err := DecryptFile(fixtureBasicYAMLFile, "yaml", DecryptOptions{Keys: keys}); err != nil {
+		t.Fatalf("DecryptFile() error = %v", err)
+	}
+}
+
+func TestNewKeysFromMaterialRejectsInvalidAgePluginIdentity(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewKeysFromMaterial(KeyMaterial{Age: []string{"AGE-PLUGIN-FAKE-1INVALID"}}); err == nil || !strings.Contains(err.Error(), "failed to parse age plugin identity") {
+		t.Fatalf("NewKeysFromMaterial() error = %v, want %q", err, "failed to parse age plugin identity")
+	}
+}
+
+func TestAgePluginStanzaRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, size := range []int{0, 1, 47, 48, 49, 96, 100} {
+		body := bytes.Repeat([]byte{0xa5}, size)
+
+		var buf bytes.Buffer
+		if err := writeAgePluginStanza(&buf, "recipient-stanza", []string{"0", "X25519", "abc"}, body); err != nil {
+			t.Fatalf("writeAgePluginStanza() error = %v", err)
+		}
+
+		for line := range strings.Lines(buf.String()) {
+			if len(strings.TrimSuffix(line, "\n")) > agePluginStanzaColumns {
+				t.Errorf("writeAgePluginStanza(%d bytes) line = %q, want at most %d columns", size, line, agePluginStanzaColumns)
+			}
+		}
+
+		r := bufio.NewReader(&buf)
+
+		s, err := readAgePluginStanza(r)
+		if err != nil {
+			t.Fatalf("readAgePluginStanza(%d bytes) error = %v", size, err)
+		}
+
+		if s.Type != "recipient-stanza" || strings.Join(s.Args, " ") != "0 X25519 abc" || !bytes.Equal(s.Body, body) {
+			t.Errorf("readAgePluginStanza(%d bytes) = %+v, want the written stanza", size, s)
+		}
+
+		if r.Buffered() != 0 {
+			t.Errorf("readAgePluginStanza(%d bytes) left %d bytes unread", size, r.Buffered())
+		}
+	}
+}
+
+// unwrapUserError returns the message of the given error with the word wrapping and the
+// indentation sops applies to the errors of the individual master keys removed.
+func unwrapUserError(err error) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(err.Error(), "| ", "")), " ")
+}
@@ -4,10 +4,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/getsops/sops/v3"
 	"github.com/getsops/sops/v3/aes"
 	"github.com/getsops/sops/v3/cmd/sops/common"
 	"github.com/getsops/sops/v3/cmd/sops/formats"
@@ -18,6 +20,30 @@ import (
 type DecryptOptions struct {
 	// IgnoreMACMismatch indicates whether to ignore MAC mismatch errors.
 	IgnoreMACMismatch bool
+
+	// Keys contains key material that is used instead of the key sources sops discovers from the
+	// environment. If nil, only the key sources from the environment are used.
+	Keys *Keys
+
+	// OutputFormat is the format the cleartext is emitted in. If empty, the
+	// format of the encrypted data is used.
+	OutputFormat string
+}
+
+// DecryptResult contains the cleartext of a decryption and the integrity information of the
+// decrypted document.
+type DecryptResult struct {
+	// Cleartext is the decrypted data.
+	Cleartext []byte
+
+	// MACValid indicates whether the MAC stored in the document matches the decrypted data.
+	MACValid bool
+
+	// ExpectedMACPresent indicates whether the document contains a MAC at all.
+	ExpectedMACPresent bool
+
+	// LastModified is the time the document was last modified by sops.
+	LastModified time.Time
 }
 
 // decrypt decrypts the given data using the specified format.
@@ -25,16 +51,40 @@ type DecryptOptions struct {
 // This function is mostly taken from the sops codebase and modified to allow ignoring MAC mismatch
 // errors, henceforth the function is following the license of the sops codebase, i.e.
 // MPL-2.0.
-func decrypt(data []byte, format formats.Format, opts DecryptOptions) (cleartext []byte, err error) {
+func decrypt(data []byte, format formats.Format, opts DecryptOptions) (result DecryptResult, err error) {
 	store := common.StoreForFormat(format, config.NewStoresConfig())
 
 	// Load SOPS file and access the data key
 	tree, err := store.LoadEncryptedFile(data)
 	if err != nil {
+		return result, err
+	}
+
+	if _, err := decryptTree(&tree, opts, &result); err != nil {
+		return result, err
+	}
+
+	// Emit the cleartext in the requested output format, if any
+	if opts.OutputFormat != "" {
+		store = common.StoreForFormat(formats.FormatFromString(opts.OutputFormat), config.NewStoresConfig())
+	}
+
+	result.Cleartext, err = store.EmitPlainFile(tree.Branches)
+	return result, err
+}
+
+// decryptTree decrypts the given tree in place and returns its data key. The integrity information
+// of the tree is recorded in result.
+func decryptTree(tree *sops.Tree, opts DecryptOptions, result *DecryptResult) ([]byte, error) {
+	if err := opts.Keys.err(); err != nil {
 		return nil, err
 	}
-	key, err := tree.Metadata.GetDataKey()
-	if err != nil {
+
+	key, err := tree.Metadata.GetDataKeyWithKeyServices(opts.Keys.keyServices(), nil)
+	if userErr := sops.UserError(nil); errors.As(err, &userErr) {
+		// the user error lists why each master key failed, e.g. that an age plugin was not found
+		return nil, fmt.Errorf("%w\n\n%s", err, userErr.UserError())
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -45,39 +95,60 @@ func decrypt(data []byte, format formats.Format, opts DecryptOptions) (cleartext
 		return nil, err
 	}
 
-	// Compute the original MAC of the tree if not ignoring MAC mismatch
-	if !opts.IgnoreMACMismatch {
-		// Compute the hash of the cleartext tree and compare it with
-		// the one that was stored in the document. If they match,
-		// integrity was preserved
-		originalMac, err := cipher.Decrypt(
-			tree.Metadata.MessageAuthenticationCode,
-			key,
-			tree.Metadata.LastModified.Format(time.RFC3339),
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt original mac: %w", err)
-		}
-		if originalMac != mac {
-			return nil, fmt.Errorf("failed to verify data integrity. expected mac %q, got %q", originalMac, mac)
-		}
+	result.LastModified = tree.Metadata.LastModified
+	result.ExpectedMACPresent = tree.Metadata.MessageAuthenticationCode != ""
+
+	// Compute the hash of the cleartext tree and compare it with the one that was stored in the
+	// document. If they match, integrity was preserved
+	var macErr error
+	originalMac, err := cipher.Decrypt(
+		tree.Metadata.MessageAuthenticationCode,
+		key,
+		tree.Metadata.LastModified.Format(time.RFC3339),
+	)
+	switch {
+	case err != nil:
+		macErr = fmt.Errorf("failed to decrypt original mac: %w", err)
+	case originalMac != mac:
+		macErr = fmt.Errorf("failed to verify data integrity. expected mac %q, got %q", originalMac, mac)
+	default:
+		result.MACValid = true
+	}
+
+	// Fail on a MAC mismatch if not ignoring MAC mismatch
+	if macErr != nil && !opts.IgnoreMACMismatch {
+		return nil, macErr
 	}
 
-	return store.EmitPlainFile(tree.Branches)
+	return key, nil
 }
 
 // DecryptData decrypts the given data using the specified format and options.
 func DecryptData(data []byte, format string, opts DecryptOptions) (cleartext []byte, err error) {
+	result, err := DecryptDataDetailed(data, format, opts)
+	return result.Cleartext, err
+}
+
+// DecryptDataDetailed decrypts the given data using the specified format and options and returns
+// the cleartext together with the integrity information of the data.
+func DecryptDataDetailed(data []byte, format string, opts DecryptOptions) (DecryptResult, error) {
 	formatEnum := formats.FormatFromString(format)
 	return decrypt(data, formatEnum, opts)
 }
 
 // DecryptFile decrypts the file at the given path using the specified format and options.
 func DecryptFile(path string, format string, opts DecryptOptions) (cleartext []byte, err error) {
+	result, err := DecryptFileDetailed(path, format, opts)
+	return result.Cleartext, err
+}
+
+// DecryptFileDetailed decrypts the file at the given path using the specified format and options
+// and returns the cleartext together with the integrity information of the file.
+func DecryptFileDetailed(path string, format string, opts DecryptOptions) (DecryptResult, error) {
 	// Read the file into an []byte
 	encryptedData, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+		return DecryptResult{}, fmt.Errorf("failed to read %q: %w", path, err)
 	}
 
 	formatEnum := formats.FormatFromString(format)
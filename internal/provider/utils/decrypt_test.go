@@ -0,0 +1,63 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecryptFileDetailedReportsMAC(t *testing.T) {
+	keys := mustNewKeysFromFile(t, testAgeKeyFile)
+
+	tests := []struct {
+		name         string
+		file         string
+		ignoreMAC    bool
+		wantMACValid bool
+		wantErr      bool
+	}{
+		{
+			name:         "valid mac",
+			file:         fixtureBasicYAMLFile,
+			wantMACValid: true,
+		},
+		{
+			name:    "mac mismatch",
+			file:    fixtureBasicMACMismatchYAMLFile,
+			wantErr: true,
+		},
+		{
+			name:         "mac mismatch ignored",
+			file:         fixtureBasicMACMismatchYAMLFile,
+			ignoreMAC:    true,
+			wantMACValid: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := DecryptFileDetailed(test.file, "yaml", DecryptOptions{Keys: keys, IgnoreMACMismatch: test.ignoreMAC})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("DecryptFileDetailed() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			if result.MACValid != test.wantMACValid {
+				t.Errorf("MACValid = %v, want %v", result.MACValid, test.wantMACValid)
+			}
+			if !result.ExpectedMACPresent {
+				t.Error("ExpectedMACPresent = false, want true")
+			}
+			if want := time.Date(2024, 11, 27, 20, 58, 6, 0, time.UTC); !result.LastModified.Equal(want) {
+				t.Errorf("LastModified = %v, want %v", result.LastModified, want)
+			}
+			if len(result.Cleartext) == 0 {
+				t.Error("Cleartext is empty")
+			}
+		})
+	}
+}
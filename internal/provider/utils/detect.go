@@ -0,0 +1,56 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"github.com/getsops/sops/v3"
+)
+
+// detectFormats are the formats that are tried, in order, if the format of sops encrypted data is
+// not known. YAML is a superset of JSON, so JSON has to be tried first. Binary data is stored as
+// JSON by sops and therefore detected as part of JSON.
+var detectFormats = []string{"json", "yaml", "ini", "dotenv"}
+
+// DetectEncryptedFormat reports whether the given data is a sops encrypted document and, if so, in
+// which format. If format is empty, all supported formats are tried. The data is never decrypted.
+func DetectEncryptedFormat(data []byte, format string) (string, bool) {
+	if format != "" {
+		if _, err := loadEncryptedTree(data, format); err != nil {
+			return "", false
+		}
+
+		return format, true
+	}
+
+	for _, candidate := range detectFormats {
+		tree, err := loadEncryptedTree(data, candidate)
+		if err != nil {
+			continue
+		}
+
+		if candidate == "json" && isBinaryTree(tree) {
+			return "binary", true
+		}
+
+		return candidate, true
+	}
+
+	return "", false
+}
+
+// isBinaryTree reports whether the given tree looks like binary data stored by sops, i.e. a single
+// document with nothing but a "data" key holding a string.
+func isBinaryTree(tree sops.Tree) bool {
+	if len(tree.Branches) != 1 || len(tree.Branches[0]) != 1 {
+		return false
+	}
+
+	item := tree.Branches[0][0]
+	if key, ok := item.Key.(string); !ok || key != "data" {
+		return false
+	}
+
+	_, ok := item.Value.(string)
+	return ok
+}
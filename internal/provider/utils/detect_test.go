@@ -0,0 +1,52 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDetectEncryptedFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		file       string
+		data       string
+		format     string
+		wantFormat string
+		wantOK     bool
+	}{
+		{name: "yaml", file: "../../../test/fixtures/basic.sops.yaml", wantFormat: "yaml", wantOK: true},
+		{name: "json", file: "../../../test/fixtures/basic.sops.json", wantFormat: "json", wantOK: true},
+		{name: "ini", file: "../../../test/fixtures/sample.sops.ini", wantFormat: "ini", wantOK: true},
+		{name: "dotenv", file: "../../../test/fixtures/dot.sops.env", wantFormat: "dotenv", wantOK: true},
+		{name: "binary", file: "../../../test/fixtures/raw.sops.txt", wantFormat: "binary", wantOK: true},
+		{name: "explicit format", file: "../../../test/fixtures/basic.sops.json", format: "yaml", wantFormat: "yaml", wantOK: true},
+		{name: "wrong explicit format", file: "../../../test/fixtures/basic.sops.yaml", format: "ini"},
+		{name: "plaintext yaml", data: "abc: xyz\nintegers: 123\n"},
+		{name: "plaintext json", data: `{"abc": "xyz"}`},
+		{name: "empty", data: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			data := []byte(test.data)
+			if test.file != "" {
+				var err error
+				if data, err = os.ReadFile(test.file); err != nil {
+					t.Fatalf("os.ReadFile() error = %v", err)
+				}
+			}
+
+			format, ok := DetectEncryptedFormat(data, test.format)
+			if ok != test.wantOK || format != test.wantFormat {
+				t.Errorf("DetectEncryptedFormat() = (%q, %v), want (%q, %v)", format, ok, test.wantFormat, test.wantOK)
+			}
+		})
+	}
+}
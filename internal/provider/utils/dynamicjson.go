@@ -0,0 +1,102 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// DynamicToJSON is the inverse of JSONToDynamicImplied. It converts the given value into JSON,
+// encoding objects and maps as JSON objects, lists, sets and tuples as JSON arrays, and null
+// values as JSON null. Numbers are encoded without losing precision. Unknown values are rejected.
+func DynamicToJSON(ctx context.Context, value attr.Value) ([]byte, error) {
+	tfValue, err := value.ToTerraformValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := anyFromTerraformValue(tfValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+func anyFromTerraformValue(value tftypes.Value) (any, error) {
+	if !value.IsKnown() {
+		return nil, errors.New("cannot convert unknown value to JSON")
+	}
+
+	if value.IsNull() {
+		return nil, nil
+	}
+
+	typ := value.Type()
+	switch {
+	case typ.Is(tftypes.String):
+		var s string
+		err := value.As(&s)
+		return s, err
+	case typ.Is(tftypes.Bool):
+		var b bool
+		err := value.As(&b)
+		return b, err
+	case typ.Is(tftypes.Number):
+		n := new(big.Float)
+		if err := value.As(&n); err != nil {
+			return nil, err
+		}
+
+		// keep integers in integer notation, which is what 'g' would turn into an exponent
+		if n.IsInt() {
+			return json.Number(n.Text('f', -1)), nil
+		}
+
+		return json.Number(n.Text('g', -1)), nil
+	case typ.Is(tftypes.List{}), typ.Is(tftypes.Set{}), typ.Is(tftypes.Tuple{}):
+		var elements []tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return nil, err
+		}
+
+		array := make([]any, 0, len(elements))
+		for _, element := range elements {
+			v, err := anyFromTerraformValue(element)
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, v)
+		}
+
+		return array, nil
+	case typ.Is(tftypes.Map{}), typ.Is(tftypes.Object{}):
+		var attributes map[string]tftypes.Value
+		if err := value.As(&attributes); err != nil {
+			return nil, err
+		}
+
+		object := make(map[string]any, len(attributes))
+		for key, attribute := range attributes {
+			v, err := anyFromTerraformValue(attribute)
+			if err != nil {
+				return nil, err
+			}
+
+			object[key] = v
+		}
+
+		return object, nil
+	}
+
+	return nil, fmt.Errorf("unhandled type: %s", typ)
+}
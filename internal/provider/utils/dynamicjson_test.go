@@ -0,0 +1,76 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDynamicToJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value attr.Value
+		want  string
+	}{
+		{
+			name: "round trip",
+			value: func() attr.Value {
+				v, err := JSONToDynamicImplied([]byte(`{"a":"b","c":[1,2.5,true,null],"d":{"e":9007199254740993}}`))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return v
+			}(),
+			want: `{"a":"b","c":[1,2.5,true,null],"d":{"e":9007199254740993}}`,
+		},
+		{
+			name:  "large integer",
+			value: types.DynamicValue(types.NumberValue(big.NewFloat(1e21))),
+			want:  `1000000000000000000000`,
+		},
+		{
+			name: "map",
+			value: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"b": types.StringValue("2"),
+				"a": types.StringValue("1"),
+			}),
+			want: `{"a":"1","b":"2"}`,
+		},
+		{
+			name:  "null",
+			value: types.DynamicNull(),
+			want:  `null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := DynamicToJSON(context.Background(), test.value)
+			if err != nil {
+				t.Fatalf("DynamicToJSON() error = %v", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("DynamicToJSON() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDynamicToJSONRejectsUnknown(t *testing.T) {
+	t.Parallel()
+
+	if _, err := DynamicToJSON(context.Background(), types.DynamicUnknown()); err == nil {
+		t.Error("DynamicToJSON() error = nil, want error")
+	}
+}
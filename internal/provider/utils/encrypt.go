@@ -0,0 +1,226 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	"github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/getsops/sops/v3/kms"
+	"github.com/getsops/sops/v3/pgp"
+	"github.com/getsops/sops/v3/version"
+)
+
+// KeyGroup contains the recipients of a single sops key group.
+type KeyGroup struct {
+	// Age contains the age recipients, e.g. "age1...".
+	Age []string
+	// PGP contains the PGP fingerprints.
+	PGP []string
+	// KMS contains the AWS KMS key ARNs.
+	KMS []string
+}
+
+// EncryptOptions contains options for the Encrypt function.
+type EncryptOptions struct {
+	// KeyGroups contains the key groups the data key is encrypted for. With a single key group, any
+	// of its keys can decrypt the data. With multiple key groups, the data key is split between
+	// them using Shamir's secret sharing.
+	KeyGroups []KeyGroup
+
+	// ShamirThreshold is the number of key groups required to decrypt the data. If zero, all key
+	// groups are required.
+	ShamirThreshold int
+
+	// InputFormat is the format the cleartext is read in. If empty, the output format is used.
+	InputFormat string
+}
+
+// masterKeys returns the sops master keys of the key group.
+func (g KeyGroup) masterKeys() (sops.KeyGroup, error) {
+	var group sops.KeyGroup
+
+	for _, recipient := range g.Age {
+		key, err := age.MasterKeyFromRecipient(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", recipient, err)
+		}
+		group = append(group, key)
+	}
+
+	for _, fingerprint := range g.PGP {
+		group = append(group, pgp.NewMasterKeyFromFingerprint(fingerprint))
+	}
+
+	for _, arn := range g.KMS {
+		group = append(group, kms.NewMasterKeyFromArn(arn, nil, ""))
+	}
+
+	return group, nil
+}
+
+// keyGroups returns the sops key groups for the given options.
+func (opts EncryptOptions) keyGroups() ([]sops.KeyGroup, error) {
+	groups := make([]sops.KeyGroup, 0, len(opts.KeyGroups))
+	for i, g := range opts.KeyGroups {
+		group, err := g.masterKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(group) == 0 {
+			return nil, fmt.Errorf("key group %d does not contain any keys", i)
+		}
+
+		groups = append(groups, group)
+	}
+
+	if len(groups) == 0 {
+		return nil, errors.New("at least one key is required to encrypt data")
+	}
+
+	if opts.ShamirThreshold < 0 || opts.ShamirThreshold > len(groups) {
+		return nil, fmt.Errorf("invalid shamir threshold %d for %d key groups", opts.ShamirThreshold, len(groups))
+	}
+
+	return groups, nil
+}
+
+// Encrypt encrypts the given cleartext for the key groups of the given options and returns the
+// encrypted document in the specified format.
+//
+// This function is mostly taken from the encrypt command of the sops codebase, henceforth the
+// function is following the license of the sops codebase, i.e. MPL-2.0.
+func Encrypt(cleartext []byte, format string, opts EncryptOptions) ([]byte, error) {
+	inputFormat := opts.InputFormat
+	if inputFormat == "" {
+		inputFormat = format
+	}
+
+	inputStore := common.StoreForFormat(formats.FormatFromString(inputFormat), config.NewStoresConfig())
+	outputStore := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+
+	branches, err := inputStore.LoadPlainFile(cleartext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cleartext: %w", err)
+	}
+
+	if len(branches) < 1 {
+		return nil, errors.New("cleartext cannot be completely empty, it must contain at least one document")
+	}
+
+	if outputStore.HasSopsTopLevelKey(branches[0]) {
+		return nil, errors.New("cleartext contains a top-level sops entry, it is probably already encrypted")
+	}
+
+	groups, err := opts.keyGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	tree := sops.Tree{
+		Branches: branches,
+		Metadata: sops.Metadata{
+			KeyGroups:         groups,
+			UnencryptedSuffix: sops.DefaultUnencryptedSuffix,
+			Version:           version.Version,
+			ShamirThreshold:   opts.ShamirThreshold,
+		},
+	}
+
+	dataKey, errs := tree.GenerateDataKeyWithKeyServices([]keyservice.KeyServiceClient{keyservice.NewLocalClient()})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to generate data key: %w", errors.Join(errs...))
+	}
+
+	err = common.EncryptTree(common.EncryptTreeOpts{
+		DataKey: dataKey,
+		Tree:    &tree,
+		Cipher:  aes.NewCipher(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outputStore.EmitEncryptedFile(tree)
+}
+
+// EncryptFile encrypts the given cleartext like Encrypt and writes the result to the file at the
+// given path. The file is replaced atomically, so readers never observe a partially written file.
+func EncryptFile(path string, cleartext []byte, format string, opts EncryptOptions) ([]byte, error) {
+	encrypted, err := Encrypt(cleartext, format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := WriteFileAtomic(path, encrypted, 0o644); err != nil {
+		return nil, err
+	}
+
+	return encrypted, nil
+}
+
+// NormalizeCleartext loads the given cleartext in the input format and emits it in the specified
+// format, so that cleartexts that only differ in their formatting compare equal. If inputFormat is
+// empty, format is used.
+func NormalizeCleartext(cleartext []byte, inputFormat, format string) ([]byte, error) {
+	if inputFormat == "" {
+		inputFormat = format
+	}
+
+	inputStore := common.StoreForFormat(formats.FormatFromString(inputFormat), config.NewStoresConfig())
+	outputStore := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+
+	branches, err := inputStore.LoadPlainFile(cleartext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cleartext: %w", err)
+	}
+
+	return outputStore.EmitPlainFile(branches)
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it to path afterwards.
+// Missing parent directories are created.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create parent directory of %q: %w", path, err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %q: %w", path, err)
+	}
+
+	// remove the temporary file if anything goes wrong before the rename
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to set permissions of %q: %w", path, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testAgeRecipient = "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn"
+
+func TestEncryptRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	keys := mustNewKeysFromFile(t, testAgeKeyFile)
+
+	tests := []struct {
+		name        string
+		format      string
+		inputFormat string
+		cleartext   string
+		want        string
+	}{
+		{
+			name:      "yaml",
+			format:    "yaml",
+			cleartext: "password: hunter2\nnested:\n    list:\n        - 1\n        - true\n",
+			want:      "password: hunter2\nnested:\n    list:\n        - 1\n        - true\n",
+		},
+		{
+			name:        "json input to yaml",
+			format:      "yaml",
+			inputFormat: "json",
+			cleartext:   `{"password":"hunter2"}`,
+			want:        "password: hunter2\n",
+		},
+		{
+			name:      "dotenv",
+			format:    "dotenv",
+			cleartext: "PASSWORD=hunter2\n",
+			want:      "PASSWORD=hunter2\n",
+		},
+		{
+			name:      "binary",
+			format:    "binary",
+			cleartext: "-----BEGIN CERTIFICATE-----\n",
+			want:      "-----BEGIN CERTIFICATE-----\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			encrypted, err := Encrypt([]byte(test.cleartext), test.format, EncryptOptions{
+				KeyGroups:   []KeyGroup{{Age: []string{testAgeRecipient}}},
+				InputFormat: test.inputFormat,
+			})
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+
+			if strings.Contains(string(encrypted), "hunter2") {
+				t.Fatalf("Encrypt() = %q, contains cleartext", encrypted)
+			}
+
+			if _, ok := DetectEncryptedFormat(encrypted, test.format); !ok {
+				t.Errorf("DetectEncryptedFormat() = false, want encrypted %s document", test.format)
+			}
+
+			cleartext, err := DecryptData(encrypted, test.format, DecryptOptions{Keys: keys})
+			if err != nil {
+				t.Fatalf("DecryptData() error = %v", err)
+			}
+
+			if string(cleartext) != test.want {
+				t.Errorf("DecryptData() = %q, want %q", cleartext, test.want)
+			}
+		})
+	}
+}
+
+func TestEncryptShamirThreshold(t *testing.T) {
+	t.Parallel()
+
+	prod := mustNewKeysFromFile(t, testAgeKeyFile)
+
+	pq, err := os.ReadFile(testPostQuantumAgeKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pqRecipient string
+	for _, line := range strings.Split(string(pq), "\n") {
+		if recipient, ok := strings.CutPrefix(line, "# public key: "); ok {
+			pqRecipient = recipient
+		}
+	}
+
+	encrypted, err := Encrypt([]byte("a: b\n"), "yaml", EncryptOptions{
+		KeyGroups: []KeyGroup{
+			{Age: []string{testAgeRecipient}},
+			{Age: []string{pqRecipient}},
+		},
+		ShamirThreshold: 2,
+	})
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	metadata, err := ReadMetadata(encrypted, "yaml")
+	if err != nil {
+		t.Fatalf("ReadMetadata() error = %v", err)
+	}
+
+	if metadata.ShamirThreshold != 2 || len(metadata.KeyGroups) != 2 {
+		t.Errorf("ReadMetadata() = threshold %d with %d key groups, want 2 and 2", metadata.ShamirThreshold, len(metadata.KeyGroups))
+	}
+
+	// a single key group is not enough to reach the threshold
+	if _, err := DecryptData(encrypted, "yaml", DecryptOptions{Keys: prod}); err == nil {
+		t.Error("DecryptData() error = nil, want error")
+	}
+
+	both := mustNewKeysFromFile(t, testAgeKeyFile)
+	if err := both.ageIdentities.Import(string(pq)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecryptData(encrypted, "yaml", DecryptOptions{Keys: both}); err != nil {
+		t.Errorf("DecryptData() error = %v", err)
+	}
+}
+
+func TestEncryptErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cleartext string
+		opts      EncryptOptions
+		wantErr   string
+	}{
+		{
+			name:      "no keys",
+			cleartext: "a: b\n",
+			wantErr:   "at least one key is required",
+		},
+		{
+			name:      "empty key group",
+			cleartext: "a: b\n",
+			opts:      EncryptOptions{KeyGroups: []KeyGroup{{Age: []string{testAgeRecipient}}, {}}},
+			wantErr:   "key group 1 does not contain any keys",
+		},
+		{
+			name:      "invalid recipient",
+			cleartext: "a: b\n",
+			opts:      EncryptOptions{KeyGroups: []KeyGroup{{Age: []string{"age1invalid"}}}},
+			wantErr:   `invalid age recipient "age1invalid"`,
+		},
+		{
+			name:      "threshold too high",
+			cleartext: "a: b\n",
+			opts:      EncryptOptions{KeyGroups: []KeyGroup{{Age: []string{testAgeRecipient}}}, ShamirThreshold: 2},
+			wantErr:   "invalid shamir threshold 2 for 1 key groups",
+		},
+		{
+			name:      "already encrypted",
+			cleartext: "sops:\n    version: 3.9.1\n",
+			opts:      EncryptOptions{KeyGroups: []KeyGroup{{Age: []string{testAgeRecipient}}}},
+			wantErr:   "already encrypted",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Encrypt([]byte(test.cleartext), "yaml", test.opts)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Encrypt() error = %v, want error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptFileCreatesParentDirectories(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "secret.sops.yaml")
+
+	encrypted, err := EncryptFile(path, []byte("a: b\n"), "yaml", EncryptOptions{
+		KeyGroups: []KeyGroup{{Age: []string{testAgeRecipient}}},
+	})
+	if err != nil {
+		t.Fatalf("EncryptFile() error = %v", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(written) != string(encrypted) {
+		t.Errorf("EncryptFile() wrote %q, want %q", written, encrypted)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Errorf("EncryptFile() left %d files behind, want 1", len(entries))
+	}
+}
+
+func TestNormalizeCleartext(t *testing.T) {
+	t.Parallel()
+
+	a, err := NormalizeCleartext([]byte("a:   b\nc: [1, 2]\n"), "", "yaml")
+	if err != nil {
+		t.Fatalf("NormalizeCleartext() error = %v", err)
+	}
+
+	b, err := NormalizeCleartext([]byte(`{"a": "b", "c": [1, 2]}`), "json", "yaml")
+	if err != nil {
+		t.Fatalf("NormalizeCleartext() error = %v", err)
+	}
+
+	if string(a) != string(b) {
+		t.Errorf("NormalizeCleartext() = %q and %q, want equal", a, b)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseExtractPath parses a path in either the sops --extract syntax, e.g. ["a"]["b"][0], or as a
+// JSON Pointer, e.g. /a/b/0. Object keys are returned as strings and, for the sops syntax, array
+// indices as ints. JSON Pointer segments are always returned as strings, as they can address both.
+func ParseExtractPath(path string) ([]any, error) {
+	switch {
+	case strings.HasPrefix(path, "["):
+		return parseSopsPath(path)
+	case strings.HasPrefix(path, "/"):
+		return parseJSONPointer(path), nil
+	case path == "":
+		return nil, errors.New("path must not be empty")
+	}
+
+	return nil, fmt.Errorf("invalid path %q: must either use the sops syntax, e.g. [\"a\"][0], or be a JSON Pointer, e.g. /a/0", path)
+}
+
+// parseSopsPath parses a path in the sops --extract syntax.
+func parseSopsPath(path string) ([]any, error) {
+	var segments []any
+
+	rest := path
+	for rest != "" {
+		end := strings.Index(rest, "]")
+		if rest[0] != '[' || end < 0 {
+			return nil, fmt.Errorf("invalid path %q: expected [ at %q", path, rest)
+		}
+
+		component := rest[1:end]
+		rest = rest[end+1:]
+
+		if len(component) >= 2 && (component[0] == '"' || component[0] == '\'') && component[len(component)-1] == component[0] {
+			segments = append(segments, component[1:len(component)-1])
+			continue
+		}
+
+		index, err := strconv.Atoi(component)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid path %q: %q is neither a quoted key nor an index", path, component)
+		}
+
+		segments = append(segments, index)
+	}
+
+	return segments, nil
+}
+
+// parseJSONPointer parses a JSON Pointer as defined in RFC 6901.
+func parseJSONPointer(path string) []any {
+	var segments []any
+	for _, segment := range strings.Split(path[1:], "/") {
+		segment = strings.ReplaceAll(segment, "~1", "/")
+		segment = strings.ReplaceAll(segment, "~0", "~")
+		segments = append(segments, segment)
+	}
+
+	return segments
+}
+
+// formatPath formats the given segments in the sops --extract syntax.
+func formatPath(segments []any) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		switch s := segment.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", s)
+		default:
+			fmt.Fprintf(&b, "[%q]", s)
+		}
+	}
+
+	return b.String()
+}
+
+// Extract returns the value at the given path of the decrypted data, which has to be in JSON
+// format as returned by UnmarshalDecryptedData. Objects and arrays are returned as JSON in subtree,
+// all other values as their raw string representation in raw, with subtree being nil.
+func Extract(data []byte, path string) (subtree []byte, raw string, err error) {
+	segments, err := ParseExtractPath(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(data) == 0 {
+		return nil, "", errors.New("extracting values is not supported for binary data")
+	}
+
+	value, err := decodeJSONPreservingNumbers(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i, segment := range segments {
+		parent := formatPath(segments[:i])
+		if parent == "" {
+			parent = "the document root"
+		}
+
+		switch node := value.(type) {
+		case map[string]any:
+			key, ok := segment.(string)
+			if !ok {
+				return nil, "", fmt.Errorf("path not found: %s is an object, cannot index it with [%d]", parent, segment)
+			}
+
+			child, ok := node[key]
+			if !ok {
+				return nil, "", fmt.Errorf("path not found: key %q does not exist in %s", key, parent)
+			}
+
+			value = child
+		case []any:
+			index, ok := segment.(int)
+			if !ok {
+				// JSON Pointer segments are strings, even if they address an array element
+				index, err = strconv.Atoi(segment.(string))
+				if err != nil || index < 0 {
+					return nil, "", fmt.Errorf("path not found: %s is an array, cannot index it with %q", parent, segment)
+				}
+			}
+
+			if index >= len(node) {
+				return nil, "", fmt.Errorf("path not found: index %d does not exist in %s with %d elements", index, parent, len(node))
+			}
+
+			value = node[index]
+		default:
+			return nil, "", fmt.Errorf("path not found: %s is a scalar value, cannot descend into %s", parent, formatPath(segments[i:i+1]))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any, []any, nil:
+		subtree, err = json.Marshal(v)
+		return subtree, "", err
+	case string:
+		return nil, v, nil
+	default:
+		return nil, fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExtractPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path    string
+		want    []any
+		wantErr bool
+	}{
+		{path: `["a"]["b"][0]`, want: []any{"a", "b", 0}},
+		{path: `['a'][12]`, want: []any{"a", 12}},
+		{path: `["a.b"]`, want: []any{"a.b"}},
+		{path: `/a/b/0`, want: []any{"a", "b", "0"}},
+		{path: `/a~1b/c~0d`, want: []any{"a/b", "c~d"}},
+		{path: ``, wantErr: true},
+		{path: `a.b`, wantErr: true},
+		{path: `["a"`, wantErr: true},
+		{path: `[a]`, wantErr: true},
+		{path: `["a"]b`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseExtractPath(test.path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseExtractPath() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseExtractPath() = %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"database": {"password": "hunter2", "port": 5432, "hosts": ["a", "b"], "tls": null}}`)
+
+	tests := []struct {
+		name        string
+		path        string
+		wantSubtree string
+		wantRaw     string
+		wantErr     string
+	}{
+		{name: "string", path: `["database"]["password"]`, wantRaw: "hunter2"},
+		{name: "number", path: `/database/port`, wantRaw: "5432"},
+		{name: "object", path: `["database"]["hosts"]`, wantSubtree: `["a","b"]`},
+		{name: "array element", path: `/database/hosts/1`, wantRaw: "b"},
+		{name: "null", path: `["database"]["tls"]`, wantSubtree: `null`},
+		{name: "missing key", path: `["database"]["user"]`, wantErr: `path not found: key "user" does not exist in ["database"]`},
+		{name: "missing root key", path: `/cache`, wantErr: `path not found: key "cache" does not exist in the document root`},
+		{name: "index out of range", path: `["database"]["hosts"][2]`, wantErr: `path not found: index 2 does not exist in ["database"]["hosts"] with 2 elements`},
+		{name: "scalar", path: `["database"]["port"]["x"]`, wantErr: `path not found: ["database"]["port"] is a scalar value, cannot descend into ["x"]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			subtree, raw, err := Extract(data, test.path)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Extract() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+
+			if string(subtree) != test.wantSubtree || raw != test.wantRaw {
+				t.Errorf("Extract() = (%s, %q), want (%s, %q)", subtree, raw, test.wantSubtree, test.wantRaw)
+			}
+		})
+	}
+}
+
+func TestExtractRejectsBinary(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := Extract([]byte{}, `["data"]`); err == nil {
+		t.Fatal("Extract() error = nil, want error")
+	}
+}
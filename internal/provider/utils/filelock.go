@@ -0,0 +1,38 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"fmt"
+	"os"
+)
+
+// LockFile takes an exclusive lock of the file at the given path and returns a function to release
+// it again. It serializes read-modify-write cycles on the same file, which would otherwise
+// overwrite each other's changes.
+//
+// The lock is an advisory lock of the operating system on the sidecar file <path>.lock, so that it
+// is honored by other provider processes, e.g. of other provider aliases or concurrent Terraform
+// runs, as well as by other lock handles of the same process. The file itself cannot be locked, as
+// it is replaced on every write. The sidecar file is left in place, as removing it would allow a
+// waiting process to lock a file that no longer exists.
+func LockFile(path string) (unlock func(), err error) {
+	lockPath := path + ".lock"
+
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %q: %w", lockPath, err)
+	}
+
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock %q: %w", lockPath, err)
+	}
+
+	return func() {
+		// closing the file releases the lock as well
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
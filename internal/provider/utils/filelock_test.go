@@ -0,0 +1,49 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileExcludesOtherHandles(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "secret.sops.yaml")
+
+	unlock, err := LockFile(path)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+
+	// the second lock uses a handle of its own, like another provider process would
+	acquired := make(chan func(), 1)
+	go func() {
+		unlock, err := LockFile(path)
+		if err != nil {
+			t.Errorf("LockFile() error = %v", err)
+			unlock = func() {}
+		}
+
+		acquired <- unlock
+	}()
+
+	select {
+	case unlock := <-acquired:
+		unlock()
+		t.Fatal("LockFile() acquired the lock while it was held")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case unlock := <-acquired:
+		unlock()
+	case <-time.After(5 * time.Second):
+		t.Fatal("LockFile() did not acquire the lock after it was released")
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// EncryptedFile describes a sops encrypted file found by FindEncryptedFiles.
+type EncryptedFile struct {
+	// Path is the path of the file, relative to the working directory if the root was.
+	Path string
+	// Format is the format of the file, as inferred from its extension.
+	Format string
+	// Metadata is the sops metadata of the file.
+	Metadata Metadata
+}
+
+// maxEncryptedFileSize is the size of the largest file FindEncryptedFiles loads the metadata of.
+// Larger files are skipped without being read, as they are most likely not sops documents.
+const maxEncryptedFileSize = 16 << 20
+
+// FindEncryptedFiles walks the directory tree below root and returns all sops encrypted files whose
+// path relative to root matches any of the include globs and none of the exclude globs. A file is
+// considered encrypted if it can be loaded by the sops store for the format inferred from its
+// extension, it is never decrypted. If include is empty, all files are included. The files are
+// returned in lexical order.
+//
+// Directories excluded by a glob ending in "/**", e.g. "vendor/**", and .git directories are not
+// walked at all. Files larger than 16 MiB are skipped. Entries below root that cannot be read are
+// skipped as well and returned as warnings, so that a single unreadable directory does not prevent
+// finding the other files.
+func FindEncryptedFiles(root string, include, exclude []string) ([]EncryptedFile, []error, error) {
+	includes, err := compileGlobs(include)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	excludes, err := compileGlobs(exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	excludedDirs, err := compileDirGlobs(exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files []EncryptedFile
+	var warnings []error
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the root itself must be readable
+			if path == root {
+				return err
+			}
+
+			warnings = append(warnings, fmt.Errorf("skipped %q: %w", path, err))
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if path != root && (d.Name() == ".git" || matchAny(excludedDirs, rel)) {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if (len(includes) > 0 && !matchAny(includes, rel)) || matchAny(excludes, rel) {
+			return nil
+		}
+
+		data, err := readFileLimited(path, maxEncryptedFileSize)
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("skipped %q: %w", path, err))
+			return nil
+		}
+
+		// too large to be a sops document
+		if data == nil {
+			return nil
+		}
+
+		format := FileFormatFromPath(path)
+
+		metadata, err := ReadMetadata(data, format)
+		if err != nil {
+			// not a sops document
+			return nil
+		}
+
+		files = append(files, EncryptedFile{
+			Path:     path,
+			Format:   format,
+			Metadata: metadata,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, warnings, nil
+}
+
+// readFileLimited reads the given file if it is not larger than limit bytes. It returns nil data
+// if the file is larger.
+func readFileLimited(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// the size is checked while reading, as the file may have changed since it was listed
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, nil
+	}
+
+	return data, nil
+}
+
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	globs := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		globs = append(globs, re)
+	}
+
+	return globs, nil
+}
+
+// compileDirGlobs compiles the directory prefixes of the given globs ending in "/**", which exclude
+// everything below the directories they match.
+func compileDirGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok && dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return compileGlobs(dirs)
+}
+
+func matchAny(globs []*regexp.Regexp, name string) bool {
+	for _, glob := range globs {
+		if glob.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
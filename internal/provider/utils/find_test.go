@@ -0,0 +1,231 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFindTree creates a directory tree for FindEncryptedFiles with the given files, mapping
+// slash separated paths to their contents, and returns its root.
+func writeFindTree(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() error = %v", err)
+		}
+
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("os.WriteFile() error = %v", err)
+		}
+	}
+
+	return root
+}
+
+// relPaths returns the slash separated paths of the given files relative to root.
+func relPaths(t *testing.T, root string, files []EncryptedFile) []string {
+	t.Helper()
+
+	var paths []string
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file.Path)
+		if err != nil {
+			t.Fatalf("filepath.Rel() error = %v", err)
+		}
+
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+
+	return paths
+}
+
+func TestFindEncryptedFiles(t *testing.T) {
+	t.Parallel()
+
+	yaml := mustReadFile(t, fixtureBasicYAMLFile)
+
+	root := writeFindTree(t, map[string][]byte{
+		"basic.sops.yaml":            yaml,
+		"nested/basic.sops.json":     mustReadFile(t, "../../../test/fixtures/basic.sops.json"),
+		"nested/dot.sops.env":        mustReadFile(t, "../../../test/fixtures/dot.sops.env"),
+		"plain.yaml":                 []byte("abc: xyz\n"),
+		"vendor/lib/basic.sops.yaml": yaml,
+		".git/basic.sops.yaml":       yaml,
+	})
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{
+			name: "all files",
+			want: []string{
+				"basic.sops.yaml",
+				"nested/basic.sops.json",
+				"nested/dot.sops.env",
+				"vendor/lib/basic.sops.yaml",
+			},
+		},
+		{
+			name:    "include and exclude",
+			include: []string{"**/*.yaml"},
+			exclude: []string{"vendor/**"},
+			want: []string{
+				"basic.sops.yaml",
+			},
+		},
+		{
+			name:    "exclude files of a directory only",
+			exclude: []string{"vendor/*", "nested/*.env"},
+			want: []string{
+				"basic.sops.yaml",
+				"nested/basic.sops.json",
+				"vendor/lib/basic.sops.yaml",
+			},
+		},
+		{
+			name:    "exclude nested directory",
+			exclude: []string{"**/lib/**"},
+			want: []string{
+				"basic.sops.yaml",
+				"nested/basic.sops.json",
+				"nested/dot.sops.env",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			files, warnings, err := FindEncryptedFiles(root, test.include, test.exclude)
+			if err != nil {
+				t.Fatalf("FindEncryptedFiles() error = %v", err)
+			}
+
+			if len(warnings) != 0 {
+				t.Errorf("FindEncryptedFiles() warnings = %v, want none", warnings)
+			}
+
+			if got := relPaths(t, root, files); !slices.Equal(got, test.want) {
+				t.Errorf("FindEncryptedFiles() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindEncryptedFilesReportsFormatAndRecipients(t *testing.T) {
+	t.Parallel()
+
+	root := writeFindTree(t, map[string][]byte{
+		"dot.sops.env": mustReadFile(t, "../../../test/fixtures/dot.sops.env"),
+	})
+
+	files, _, err := FindEncryptedFiles(root, nil, nil)
+	if err != nil {
+		t.Fatalf("FindEncryptedFiles() error = %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("FindEncryptedFiles() = %v, want one file", files)
+	}
+
+	file := files[0]
+	if file.Format != "dotenv" {
+		t.Errorf("Format = %q, want %q", file.Format, "dotenv")
+	}
+
+	if len(file.Metadata.KeyGroups) != 1 || file.Metadata.KeyGroups[0][0].Type != "age" {
+		t.Errorf("Metadata.KeyGroups = %v, want a single age key", file.Metadata.KeyGroups)
+	}
+}
+
+func TestFindEncryptedFilesSkipsLargeFiles(t *testing.T) {
+	t.Parallel()
+
+	// a valid sops document, which is only too large because of a leading comment
+	padding := "# " + strings.Repeat("x", maxEncryptedFileSize) + "\n"
+
+	root := writeFindTree(t, map[string][]byte{
+		"large.sops.yaml": append([]byte(padding), mustReadFile(t, fixtureBasicYAMLFile)...),
+	})
+
+	files, warnings, err := FindEncryptedFiles(root, nil, nil)
+	if err != nil {
+		t.Fatalf("FindEncryptedFiles() error = %v", err)
+	}
+
+	if len(files) != 0 || len(warnings) != 0 {
+		t.Errorf("FindEncryptedFiles() = %v, %v, want no files and no warnings", files, warnings)
+	}
+}
+
+func TestFindEncryptedFilesReportsUnreadableEntries(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+
+	yaml := mustReadFile(t, fixtureBasicYAMLFile)
+
+	root := writeFindTree(t, map[string][]byte{
+		"basic.sops.yaml":               yaml,
+		"locked/basic.sops.yaml":        yaml,
+		"unreadable.sops.yaml":          yaml,
+		"vendor/locked/basic.sops.yaml": yaml,
+	})
+
+	for _, name := range []string{"locked", "vendor/locked"} {
+		if err := os.Chmod(filepath.Join(root, name), 0); err != nil {
+			t.Fatalf("os.Chmod() error = %v", err)
+		}
+	}
+
+	if err := os.Chmod(filepath.Join(root, "unreadable.sops.yaml"), 0); err != nil {
+		t.Fatalf("os.Chmod() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		// allow the temporary directory to be removed
+		_ = os.Chmod(filepath.Join(root, "locked"), 0o755)
+		_ = os.Chmod(filepath.Join(root, "vendor/locked"), 0o755)
+	})
+
+	// the excluded directory is never read, so it is not reported
+	files, warnings, err := FindEncryptedFiles(root, nil, []string{"vendor/**"})
+	if err != nil {
+		t.Fatalf("FindEncryptedFiles() error = %v", err)
+	}
+
+	if got, want := relPaths(t, root, files), []string{"basic.sops.yaml"}; !slices.Equal(got, want) {
+		t.Errorf("FindEncryptedFiles() = %v, want %v", got, want)
+	}
+
+	if len(warnings) != 2 ||
+		!strings.Contains(warnings[0].Error(), filepath.Join(root, "locked")) ||
+		!strings.Contains(warnings[1].Error(), filepath.Join(root, "unreadable.sops.yaml")) {
+		t.Errorf("FindEncryptedFiles() warnings = %v, want the locked directory and the unreadable file", warnings)
+	}
+}
+
+func TestFindEncryptedFilesFailsForMissingRoot(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := FindEncryptedFiles(filepath.Join(t.TempDir(), "missing"), nil, nil); err == nil {
+		t.Fatal("FindEncryptedFiles() error = nil, want an error")
+	}
+}
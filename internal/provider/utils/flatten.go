@@ -0,0 +1,60 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Flatten flattens the decrypted data in JSON format, as returned by UnmarshalDecryptedData, into
+// a map of strings. Nested keys are joined by dots and array elements are addressed by their
+// index, e.g. "a.b.0". Null values are returned as "null". This matches the data attribute of the
+// data sources of carlpett/terraform-provider-sops. Empty data, e.g. for binary files, results in
+// an empty map. The elements of an array at the root are keyed by their index, e.g. "0.a".
+func Flatten(data []byte) (map[string]string, error) {
+	flattened := map[string]string{}
+	if len(data) == 0 {
+		return flattened, nil
+	}
+
+	value, err := decodeJSONPreservingNumbers(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root := value.(type) {
+	case map[string]any:
+		for key, child := range root {
+			flattenInto(flattened, key, child)
+		}
+	case []any:
+		// e.g. an extracted list, whose elements are addressed by their index only
+		for i, child := range root {
+			flattenInto(flattened, strconv.Itoa(i), child)
+		}
+	default:
+		return nil, fmt.Errorf("expected an object or an array at the document root, got %T", value)
+	}
+
+	return flattened, nil
+}
+
+// flattenInto adds value and all its children to flattened, using prefix as key.
+func flattenInto(flattened map[string]string, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			flattenInto(flattened, prefix+"."+key, child)
+		}
+	case []any:
+		for i, child := range v {
+			flattenInto(flattened, prefix+"."+strconv.Itoa(i), child)
+		}
+	case nil:
+		flattened[prefix] = "null"
+	default:
+		flattened[prefix] = fmt.Sprint(v)
+	}
+}
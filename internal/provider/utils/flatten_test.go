@@ -0,0 +1,63 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"a": {"b": [1, "two", {"c": true}], "d": null}, "e": 3.14e-10}`)
+
+	got, err := Flatten(data)
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+
+	want := map[string]string{
+		"a.b.0":   "1",
+		"a.b.1":   "two",
+		"a.b.2.c": "true",
+		"a.d":     "null",
+		"e":       "3.14e-10",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() = %v, want %v", got, want)
+	}
+}
+
+func TestFlattenEmpty(t *testing.T) {
+	t.Parallel()
+
+	got, err := Flatten([]byte{})
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("Flatten() = %v, want empty map", got)
+	}
+}
+
+func TestFlattenArray(t *testing.T) {
+	t.Parallel()
+
+	got, err := Flatten([]byte(`[{"a": 1}, "two"]`))
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+
+	want := map[string]string{
+		"0.a": "1",
+		"1":   "two",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() = %v, want %v", got, want)
+	}
+}
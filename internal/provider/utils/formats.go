@@ -63,6 +63,23 @@ func FileFormatFromPath(path string) string {
 	return "binary"
 }
 
+// FileExtensionForFormat returns the conventional file extension for the given format, or an
+// empty string for binary data.
+func FileExtensionForFormat(format string) string {
+	switch format {
+	case "yaml":
+		return ".yaml"
+	case "json":
+		return ".json"
+	case "dotenv":
+		return ".env"
+	case "ini":
+		return ".ini"
+	}
+
+	return ""
+}
+
 func ReadYAML(data []byte) ([]byte, error) {
 	var v any
 	err := yaml.Unmarshal(data, &v)
@@ -0,0 +1,90 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// globToRegexp translates the given glob pattern into a regular expression. "*" and "?" match
+// within a single path segment, "**" matches across segments, and "[...]" is a character class.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**/") {
+				// any number of leading directories, including none
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob %q: unterminated character class", pattern)
+			}
+
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
+
+// MatchGlob reports whether the given slash separated path matches the glob pattern. In addition to
+// the syntax of path.Match, "**" matches any number of path segments, e.g. "**/*.sops.yaml".
+func MatchGlob(pattern, name string) (bool, error) {
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(name), nil
+}
+
+// SplitGlob splits the given slash separated glob pattern into the directory all of its matches
+// are below, i.e. its leading segments without any wildcards, and the remaining pattern relative to
+// that directory. The remaining pattern is empty if the given pattern does not contain any
+// wildcards at all.
+func SplitGlob(pattern string) (dir string, rest string) {
+	segments := strings.Split(pattern, "/")
+
+	for i, segment := range segments {
+		if !strings.ContainsAny(segment, "*?[") {
+			continue
+		}
+
+		switch dir = strings.Join(segments[:i], "/"); {
+		case dir == "" && i > 0:
+			dir = "/"
+		case dir == "":
+			dir = "."
+		}
+
+		return dir, strings.Join(segments[i:], "/")
+	}
+
+	return pattern, ""
+}
@@ -0,0 +1,80 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.yaml", name: "secrets.yaml", want: true},
+		{pattern: "*.yaml", name: "env/secrets.yaml", want: false},
+		{pattern: "**/*.yaml", name: "secrets.yaml", want: true},
+		{pattern: "**/*.yaml", name: "env/prod/secrets.yaml", want: true},
+		{pattern: "env/**", name: "env/prod/secrets.yaml", want: true},
+		{pattern: "env/**", name: "other/secrets.yaml", want: false},
+		{pattern: "env/*/secrets.yaml", name: "env/prod/secrets.yaml", want: true},
+		{pattern: "env/*/secrets.yaml", name: "env/prod/eu/secrets.yaml", want: false},
+		{pattern: "secret?.json", name: "secret1.json", want: true},
+		{pattern: "secret[0-9].json", name: "secreta.json", want: false},
+		{pattern: "secret[!0-9].json", name: "secreta.json", want: true},
+		{pattern: "a.b", name: "axb", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := MatchGlob(test.pattern, test.name)
+			if err != nil {
+				t.Fatalf("MatchGlob() error = %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchGlobRejectsInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	if _, err := MatchGlob("secret[0-9.json", "secret1.json"); err == nil {
+		t.Error("MatchGlob() error = nil, want error")
+	}
+}
+
+func TestSplitGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern  string
+		wantDir  string
+		wantRest string
+	}{
+		{pattern: "secrets/prod.sops.yaml", wantDir: "secrets/prod.sops.yaml", wantRest: ""},
+		{pattern: "secrets/**/*.sops.yaml", wantDir: "secrets", wantRest: "**/*.sops.yaml"},
+		{pattern: "*.sops.yaml", wantDir: ".", wantRest: "*.sops.yaml"},
+		{pattern: "/srv/env/*/secrets.json", wantDir: "/srv/env", wantRest: "*/secrets.json"},
+		{pattern: "/*.json", wantDir: "/", wantRest: "*.json"},
+		{pattern: "env/secret[0-9].yaml", wantDir: "env", wantRest: "secret[0-9].yaml"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			dir, rest := SplitGlob(test.pattern)
+			if dir != test.wantDir || rest != test.wantRest {
+				t.Errorf("SplitGlob(%q) = (%q, %q), want (%q, %q)", test.pattern, dir, rest, test.wantDir, test.wantRest)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getsops/sops/v3"
+)
+
+// encryptedValueType matches the type tag of a value encrypted by sops, e.g. ENC[...,type:str].
+var encryptedValueType = regexp.MustCompile(`^ENC\[AES256_GCM,.*,type:([a-z]+)\]$`)
+
+// KeyPath describes a single leaf of a sops encrypted document.
+type KeyPath struct {
+	// Document is the index of the document the leaf belongs to. Only YAML files can contain more
+	// than one document.
+	Document int
+	// Path is the path of the leaf with its segments joined by dots, e.g. "a.b.0".
+	Path string
+	// ExtractPath is the path of the leaf in the sops --extract syntax, e.g. ["a"]["b"][0].
+	ExtractPath string
+	// Type is the sops type tag of the value, e.g. "str", "int", "float", "bool", or "bytes". For
+	// values that are not encrypted, the type is derived from the value itself.
+	Type string
+	// Encrypted indicates whether the value is encrypted. Values are not encrypted if they are
+	// excluded by the unencrypted_suffix or similar settings of the document.
+	Encrypted bool
+}
+
+// ListKeyPaths returns the paths of all leaves of the given encrypted data using the specified
+// format, in document order. The data is never decrypted, as the keys of a sops document are
+// stored in plaintext.
+func ListKeyPaths(data []byte, format string) ([]KeyPath, error) {
+	if format == "binary" {
+		return nil, fmt.Errorf("listing key paths is not supported for binary data")
+	}
+
+	tree, err := loadEncryptedTree(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPaths := []KeyPath{}
+	for document, branch := range tree.Branches {
+		keyPaths = appendKeyPaths(keyPaths, document, nil, branch)
+	}
+
+	return keyPaths, nil
+}
+
+// ListKeyPathsFile returns the paths of all leaves of the file at the given path using the
+// specified format.
+func ListKeyPathsFile(path string, format string) ([]KeyPath, error) {
+	encryptedData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	return ListKeyPaths(encryptedData, format)
+}
+
+// appendKeyPaths walks the given value and appends a KeyPath for every leaf to keyPaths.
+func appendKeyPaths(keyPaths []KeyPath, document int, segments []any, value any) []KeyPath {
+	switch v := value.(type) {
+	case sops.TreeBranch:
+		for _, item := range v {
+			// comments are stored as items of their own
+			if _, ok := item.Key.(sops.Comment); ok {
+				continue
+			}
+
+			keyPaths = appendKeyPaths(keyPaths, document, appendSegment(segments, fmt.Sprint(item.Key)), item.Value)
+		}
+
+		return keyPaths
+	case []any:
+		for i, element := range v {
+			if _, ok := element.(sops.Comment); ok {
+				continue
+			}
+
+			keyPaths = appendKeyPaths(keyPaths, document, appendSegment(segments, i), element)
+		}
+
+		return keyPaths
+	}
+
+	valueType, encrypted := leafType(value)
+
+	return append(keyPaths, KeyPath{
+		Document:    document,
+		Path:        joinPath(segments),
+		ExtractPath: formatPath(segments),
+		Type:        valueType,
+		Encrypted:   encrypted,
+	})
+}
+
+// appendSegment returns a copy of segments with segment appended, so that sibling paths do not
+// share their backing array.
+func appendSegment(segments []any, segment any) []any {
+	return append(segments[:len(segments):len(segments)], segment)
+}
+
+// joinPath joins the given segments with dots.
+func joinPath(segments []any) string {
+	parts := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch s := segment.(type) {
+		case int:
+			parts = append(parts, strconv.Itoa(s))
+		default:
+			parts = append(parts, fmt.Sprint(s))
+		}
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// leafType returns the sops type tag of the given leaf value and whether it is encrypted.
+func leafType(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		if match := encryptedValueType.FindStringSubmatch(v); match != nil {
+			return match[1], true
+		}
+
+		return "str", false
+	case int, int64:
+		return "int", false
+	case float64:
+		return "float", false
+	case bool:
+		return "bool", false
+	case []byte:
+		return "bytes", false
+	case nil:
+		return "null", false
+	}
+
+	return fmt.Sprintf("%T", value), false
+}
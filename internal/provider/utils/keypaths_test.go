@@ -0,0 +1,48 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestListKeyPaths(t *testing.T) {
+	t.Parallel()
+
+	encrypted, err := os.ReadFile(fixtureBasicYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	// values covered by the unencrypted_suffix are stored in plaintext
+	data := append([]byte("nested:\n    port_unencrypted: 5432\n    hosts_unencrypted:\n        - db1\n"), encrypted...)
+
+	got, err := ListKeyPaths(data, "yaml")
+	if err != nil {
+		t.Fatalf("ListKeyPaths() error = %v", err)
+	}
+
+	want := []KeyPath{
+		{Path: "nested.port_unencrypted", ExtractPath: `["nested"]["port_unencrypted"]`, Type: "int"},
+		{Path: "nested.hosts_unencrypted.0", ExtractPath: `["nested"]["hosts_unencrypted"][0]`, Type: "str"},
+		{Path: "abc", ExtractPath: `["abc"]`, Type: "str", Encrypted: true},
+		{Path: "integers", ExtractPath: `["integers"]`, Type: "int", Encrypted: true},
+		{Path: "truthy", ExtractPath: `["truthy"]`, Type: "bool", Encrypted: true},
+		{Path: "floats", ExtractPath: `["floats"]`, Type: "float", Encrypted: true},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListKeyPaths() = %+v, want %+v", got, want)
+	}
+}
+
+func TestListKeyPathsRejectsBinary(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ListKeyPathsFile("../../../test/fixtures/raw.sops.txt", "binary"); err == nil {
+		t.Fatal("ListKeyPathsFile() error = nil, want error")
+	}
+}
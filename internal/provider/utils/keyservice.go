@@ -0,0 +1,238 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/keyservice"
+)
+
+// Keys contains key material used to decrypt sops data keys instead of the key sources sops
+// discovers from the process environment. Keys are immutable once created, so a single value can
+// safely be shared between concurrent decryptions, while separate values never see each other's
+// key material.
+type Keys struct {
+	// ageIdentities contains the identities used to decrypt age keys, including SSH keys.
+	ageIdentities age.ParsedIdentities
+	// pgpEntities contains the private keys used to decrypt PGP keys.
+	pgpEntities openpgp.EntityList
+	// unavailable is the reason why the key material is not available, see NewUnavailableKeys.
+	unavailable error
+}
+
+// KeyMaterial is the key material Keys are created from, see NewKeysFromMaterial.
+type KeyMaterial struct {
+	// Age contains age identities. Each entry may contain multiple identities, one per line. Empty
+	// lines and lines starting with "#" are ignored. Plugin identities are run as configured by
+	// AgePlugins.
+	Age []string
+	// AgePlugins configures how the age plugins of plugin identities are run.
+	AgePlugins AgePluginOptions
+	// PGPArmored contains ASCII armored PGP private keys. Each entry may contain multiple keys.
+	// Passphrase-protected keys are not supported.
+	PGPArmored []string
+	// SSH contains SSH private keys used as age identities.
+	SSH []SSHKey
+}
+
+// SSHKey is an SSH ed25519 or RSA private key used as an age identity, see AgeRecipient for its
+// recipient.
+type SSHKey struct {
+	// PrivateKey is the PEM encoded private key, e.g. the content of ~/.ssh/id_ed25519.
+	PrivateKey string
+	// Passphrase is the passphrase of a passphrase-protected private key. It is ignored for
+	// unencrypted keys.
+	Passphrase string
+}
+
+// NewKeys parses the given age identities and returns the resulting Keys. Each entry may contain
+// multiple identities, one per line. Empty lines and lines starting with "#" are ignored.
+func NewKeys(ageIdentities ...string) (*Keys, error) {
+	return NewKeysFromMaterial(KeyMaterial{Age: ageIdentities})
+}
+
+// NewKeysFromMaterial parses the given key material and returns the resulting Keys. Key types
+// without key material are still looked up by sops in the process environment.
+func NewKeysFromMaterial(material KeyMaterial) (*Keys, error) {
+	keys := &Keys{}
+
+	// plugin identities are split off, as sops would run their plugins interactively. They are tried
+	// after all other identities, as running a plugin is comparatively slow.
+	var native []string
+	var plugins age.ParsedIdentities
+	for _, identities := range material.Age {
+		var lines []string
+		for line := range strings.Lines(identities) {
+			if !isAgePluginIdentity(line) {
+				lines = append(lines, line)
+				continue
+			}
+
+			identity, err := newAgePluginIdentity(strings.TrimSpace(line), material.AgePlugins)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse age plugin identity: %w", err)
+			}
+
+			plugins = append(plugins, identity)
+		}
+
+		native = append(native, strings.Join(lines, ""))
+	}
+
+	if len(native) > 0 {
+		if err := keys.ageIdentities.Import(native...); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, armored := range material.PGPArmored {
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PGP key %d: %w", i, err)
+		}
+
+		for _, entity := range entities {
+			if err := checkPGPPrivateKey(entity); err != nil {
+				return nil, fmt.Errorf("invalid PGP key %d (%X): %w", i, entity.PrimaryKey.Fingerprint, err)
+			}
+		}
+
+		keys.pgpEntities = append(keys.pgpEntities, entities...)
+	}
+
+	for i, key := range material.SSH {
+		identity, err := sshAgeIdentity(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH key %d: %w", i, err)
+		}
+
+		keys.ageIdentities = append(keys.ageIdentities, identity)
+	}
+
+	keys.ageIdentities = append(keys.ageIdentities, plugins...)
+
+	return keys, nil
+}
+
+// NewUnavailableKeys returns Keys that fail every decryption with the given error, e.g. because
+// the key material is not known yet. Unlike nil Keys, they never fall back to the key sources
+// sops discovers from the process environment.
+func NewUnavailableKeys(err error) *Keys {
+	return &Keys{unavailable: err}
+}
+
+// checkPGPPrivateKey returns an error if the given entity does not contain an unencrypted private
+// key, as the data key could not be decrypted with it without prompting for a passphrase.
+func checkPGPPrivateKey(entity *openpgp.Entity) error {
+	if entity.PrivateKey == nil {
+		return errors.New("not a private key")
+	}
+
+	if entity.PrivateKey.Encrypted {
+		return errors.New("passphrase-protected PGP keys are not supported")
+	}
+
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			return errors.New("passphrase-protected PGP keys are not supported")
+		}
+	}
+
+	return nil
+}
+
+// err returns the reason why the key material is not available, if any. It is safe to call on a
+// nil receiver.
+func (k *Keys) err() error {
+	if k == nil {
+		return nil
+	}
+
+	return k.unavailable
+}
+
+// keyServices returns the key services used to decrypt the data key. It is safe to call on a nil
+// receiver, in which case the default local key service of sops is used.
+func (k *Keys) keyServices() []keyservice.KeyServiceClient {
+	if k == nil || (len(k.ageIdentities) == 0 && len(k.pgpEntities) == 0) {
+		return []keyservice.KeyServiceClient{keyservice.NewLocalClient()}
+	}
+
+	return []keyservice.KeyServiceClient{
+		keyservice.NewCustomLocalClient(keyServiceServer{keys: k}),
+	}
+}
+
+// keyServiceServer is a local sops key service that decrypts age and PGP keys with the key
+// material of the given Keys instead of looking it up in the environment. All other requests, and
+// requests for key types without key material, are handled by the default sops key service.
+type keyServiceServer struct {
+	keyservice.Server
+
+	keys *Keys
+}
+
+// Decrypt decrypts the given ciphertext with the given key.
+func (ks keyServiceServer) Decrypt(ctx context.Context, req *keyservice.DecryptRequest) (*keyservice.DecryptResponse, error) {
+	switch key := req.Key.GetKeyType().(type) {
+	case *keyservice.Key_AgeKey:
+		if len(ks.keys.ageIdentities) > 0 {
+			return ks.decryptAge(key.AgeKey, req.Ciphertext)
+		}
+	case *keyservice.Key_PgpKey:
+		if len(ks.keys.pgpEntities) > 0 {
+			return ks.decryptPGP(key.PgpKey, req.Ciphertext)
+		}
+	}
+
+	return ks.Server.Decrypt(ctx, req)
+}
+
+// decryptAge decrypts the given age encrypted data key with the age identities of the Keys.
+func (ks keyServiceServer) decryptAge(ageKey *keyservice.AgeKey, ciphertext []byte) (*keyservice.DecryptResponse, error) {
+	// a fresh master key per request, so that the injected identities never leak into other
+	// requests
+	key := age.MasterKey{
+		Recipient:    ageKey.Recipient,
+		EncryptedKey: string(ciphertext),
+	}
+	ks.keys.ageIdentities.ApplyToMasterKey(&key)
+
+	plaintext, err := key.Decrypt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age key for recipient %s: %w", ageKey.Recipient, err)
+	}
+
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
+
+// decryptPGP decrypts the given armored PGP message with the PGP keys of the Keys, without using
+// GnuPG or its agent.
+func (ks keyServiceServer) decryptPGP(pgpKey *keyservice.PgpKey, ciphertext []byte) (*keyservice.DecryptResponse, error) {
+	block, err := armor.Decode(bytes.NewReader(ciphertext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt PGP key %s: %w", pgpKey.Fingerprint, err)
+	}
+
+	message, err := openpgp.ReadMessage(block.Body, ks.keys.pgpEntities, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt PGP key %s: %w", pgpKey.Fingerprint, err)
+	}
+
+	plaintext, err := io.ReadAll(message.UnverifiedBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt PGP key %s: %w", pgpKey.Fingerprint, err)
+	}
+
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
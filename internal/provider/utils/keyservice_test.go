@@ -0,0 +1,271 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	fixtureBasicYAMLFile            = "../../../test/fixtures/basic.sops.yaml"
+	fixturePostQuantumYAMLFile      = "../../../test/fixtures/post-quantum.sops.yaml"
+	fixtureBasicMACMismatchYAMLFile = "../../../test/fixtures/basic-mac-mismatch.sops.yaml"
+	fixturePGPYAMLFile              = "../../../test/fixtures/pgp.sops.yaml"
+	fixtureSSHYAMLFile              = "../../../test/fixtures/ssh.sops.yaml"
+	testAgeKeyFile                  = "../../../test/age.key"
+	testPostQuantumAgeKeyFile       = "../../../test/age-pq.key"
+	testSSHEd25519KeyFile           = "../../../test/ssh-ed25519.key"
+	testSSHEd25519EncryptedKeyFile  = "../../../test/ssh-ed25519-encrypted.key"
+	testSSHRSAKeyFile               = "../../../test/ssh-rsa.key"
+	testSSHRSAEncryptedKeyFile      = "../../../test/ssh-rsa-encrypted.key"
+	testSSHKeyPassphrase            = "correct horse battery staple"
+	testPGPKeyFile                  = "../../../test/pgp.key"
+)
+
+func mustNewKeysFromFile(t *testing.T, path string) *Keys {
+	t.Helper()
+
+	identity, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	keys, err := NewKeys(string(identity))
+	if err != nil {
+		t.Fatalf("NewKeys() error = %v", err)
+	}
+
+	return keys
+}
+
+func TestNewKeysRejectsInvalidIdentity(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewKeys("AGE-SECRET-KEY-INVALID"); err == nil {
+		t.Fatal("NewKeys() error = nil, want error")
+	}
+}
+
+func TestNewKeysFromMaterialRejectsInvalidPGPKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr string
+	}{
+		{name: "garbage", key: "not a key", wantErr: "failed to parse PGP key 0"},
+		{name: "public key", key: string(mustReadFile(t, testPGPKeyFile+".pub")), wantErr: "not a private key"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewKeysFromMaterial(KeyMaterial{PGPArmored: []string{test.key}})
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("NewKeysFromMaterial() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecryptFileWithPGPKeys(t *testing.T) {
+	// make sure the key cannot be discovered from a keyring or the agent
+	t.Setenv("GNUPGHOME", t.TempDir())
+
+	keys, err := NewKeysFromMaterial(KeyMaterial{
+		Age:        []string{string(mustReadFile(t, testAgeKeyFile))},
+		PGPArmored: []string{string(mustReadFile(t, testPGPKeyFile))},
+	})
+	if err != nil {
+		t.Fatalf("NewKeysFromMaterial() error = %v", err)
+	}
+
+	// both key types can be used with the same keys
+	for _, file := range []string{fixturePGPYAMLFile, fixtureBasicYAMLFile} {
+		cleartext, err := DecryptFile(file, "yaml", DecryptOptions{Keys: keys})
+		if err != nil {
+			t.Fatalf("DecryptFile(%s) error = %v", file, err)
+		}
+
+		if want := "abc: xyz\nintegers: 123\ntruthy: true\nfloats: 3.14e-10\n"; string(cleartext) != want {
+			t.Errorf("DecryptFile(%s) = %q, want %q", file, cleartext, want)
+		}
+	}
+
+	// without the PGP key, the data key cannot be decrypted
+	if _, err := DecryptFile(fixturePGPYAMLFile, "yaml", DecryptOptions{Keys: mustNewKeysFromFile(t, testAgeKeyFile)}); err == nil {
+		t.Error("DecryptFile() error = nil, want error")
+	}
+}
+
+func TestNewKeysFromMaterialRejectsInvalidSSHKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		key     SSHKey
+		wantErr string
+	}{
+		{name: "garbage", key: SSHKey{PrivateKey: "not a key"}, wantErr: "failed to parse SSH private key"},
+		{name: "public key", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHEd25519KeyFile+".pub"))}, wantErr: "failed to parse SSH private key"},
+		{name: "missing passphrase", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHRSAEncryptedKeyFile))}, wantErr: "no passphrase was provided"},
+		{name: "wrong passphrase", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHEd25519EncryptedKeyFile)), Passphrase: "hunter2"}, wantErr: "incorrect passphrase"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewKeysFromMaterial(KeyMaterial{SSH: []SSHKey{test.key}})
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("NewKeysFromMaterial() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecryptFileWithSSHKeys(t *testing.T) {
+	// make sure no SSH keys can be discovered from the environment
+	t.Setenv("SOPS_AGE_SSH_PRIVATE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_SSH_PRIVATE_KEY_CMD", "")
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name string
+		key  SSHKey
+	}{
+		{name: "ed25519", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHEd25519KeyFile))}},
+		{name: "RSA", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHRSAKeyFile))}},
+		{name: "encrypted ed25519", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHEd25519EncryptedKeyFile)), Passphrase: testSSHKeyPassphrase}},
+		{name: "encrypted RSA", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHRSAEncryptedKeyFile)), Passphrase: testSSHKeyPassphrase}},
+		// the passphrase is ignored for unencrypted keys
+		{name: "unused passphrase", key: SSHKey{PrivateKey: string(mustReadFile(t, testSSHEd25519KeyFile)), Passphrase: testSSHKeyPassphrase}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := NewKeysFromMaterial(KeyMaterial{SSH: []SSHKey{test.key}})
+			if err != nil {
+				t.Fatalf("NewKeysFromMaterial() error = %v", err)
+			}
+
+			cleartext, err := DecryptFile(fixtureSSHYAMLFile, "yaml", DecryptOptions{Keys: keys})
+			if err != nil {
+				t.Fatalf("DecryptFile() error = %v", err)
+			}
+
+			if want := "abc: xyz\nintegers: 123\ntruthy: true\nfloats: 3.14e-10\n"; string(cleartext) != want {
+				t.Errorf("DecryptFile() = %q, want %q", cleartext, want)
+			}
+
+			// the SSH key is not a recipient of the file
+			if _, err := DecryptFile(fixtureBasicYAMLFile, "yaml", DecryptOptions{Keys: keys}); err == nil {
+				t.Error("DecryptFile() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestDecryptFileWithKeysIsIsolated(t *testing.T) {
+	// make sure no identities can be discovered from the environment
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_KEY_CMD", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	prod := mustNewKeysFromFile(t, testAgeKeyFile)
+	staging := mustNewKeysFromFile(t, testPostQuantumAgeKeyFile)
+
+	tests := []struct {
+		name    string
+		keys    *Keys
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "prod decrypts prod file",
+			keys: prod,
+			file: fixtureBasicYAMLFile,
+			want: "abc: xyz",
+		},
+		{
+			name: "staging decrypts staging file",
+			keys: staging,
+			file: fixturePostQuantumYAMLFile,
+			want: "message: post-quantum works",
+		},
+		{
+			name:    "prod cannot decrypt staging file",
+			keys:    prod,
+			file:    fixturePostQuantumYAMLFile,
+			wantErr: true,
+		},
+		{
+			name:    "staging cannot decrypt prod file",
+			keys:    staging,
+			file:    fixtureBasicYAMLFile,
+			wantErr: true,
+		},
+	}
+
+	// run every case many times concurrently to surface any shared state between the key sets
+	const iterations = 25
+
+	var wg sync.WaitGroup
+	errs := make(chan string, len(tests)*iterations)
+
+	for range iterations {
+		for _, test := range tests {
+			wg.Go(func() {
+				cleartext, err := DecryptFile(test.file, "yaml", DecryptOptions{Keys: test.keys})
+				switch {
+				case test.wantErr && err == nil:
+					errs <- test.name + ": DecryptFile() error = nil, want error"
+				case !test.wantErr && err != nil:
+					errs <- test.name + ": DecryptFile() error = " + err.Error()
+				case !test.wantErr && !strings.Contains(string(cleartext), test.want):
+					errs <- test.name + ": DecryptFile() = " + string(cleartext) + ", want " + test.want
+				}
+			})
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestDecryptFileWithoutKeysUsesEnvironment(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY_FILE", testPostQuantumAgeKeyFile)
+
+	cleartext, err := DecryptFile(fixturePostQuantumYAMLFile, "yaml", DecryptOptions{})
+	if err != nil {
+		t.Fatalf("DecryptFile() error = %v", err)
+	}
+
+	if !strings.Contains(string(cleartext), "post-quantum works") {
+		t.Errorf("DecryptFile() = %q, want it to contain %q", cleartext, "post-quantum works")
+	}
+}
+
+func TestDecryptFileWithUnavailableKeys(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY_FILE", testPostQuantumAgeKeyFile)
+
+	want := errors.New("keys are not available")
+
+	if _, err := DecryptFile(fixturePostQuantumYAMLFile, "yaml", DecryptOptions{Keys: NewUnavailableKeys(want)}); !errors.Is(err, want) {
+		t.Fatalf("DecryptFile() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keys"
+	"github.com/getsops/sops/v3/kms"
+)
+
+// Metadata contains the metadata of a sops encrypted document. It is read from the document
+// without decrypting it, so no key material is needed.
+type Metadata struct {
+	LastModified            time.Time
+	Version                 string
+	UnencryptedSuffix       string
+	EncryptedSuffix         string
+	UnencryptedRegex        string
+	EncryptedRegex          string
+	UnencryptedCommentRegex string
+	EncryptedCommentRegex   string
+	MACOnlyEncrypted        bool
+	MACPresent              bool
+	ShamirThreshold         int
+	KeyGroups               [][]MasterKey
+}
+
+// MasterKey describes a single master key of a key group.
+type MasterKey struct {
+	// Type is the sops key type identifier, e.g. "age", "pgp" or "kms".
+	Type string
+	// Identifier identifies the key within its type, e.g. the age recipient, the PGP fingerprint
+	// or the KMS ARN.
+	Identifier string
+}
+
+// loadEncryptedTree loads the given encrypted data into a sops tree without decrypting it.
+func loadEncryptedTree(data []byte, format string) (sops.Tree, error) {
+	store := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+	return store.LoadEncryptedFile(data)
+}
+
+// masterKeyIdentifier returns the identifier of the given master key.
+func masterKeyIdentifier(key keys.MasterKey) string {
+	// the string representation of KMS keys includes the role and the encryption context
+	if kmsKey, ok := key.(*kms.MasterKey); ok {
+		return kmsKey.Arn
+	}
+
+	return key.ToString()
+}
+
+// masterKeys returns the descriptions of the given sops master keys.
+func masterKeys(sopsKeys []keys.MasterKey) []MasterKey {
+	result := make([]MasterKey, 0, len(sopsKeys))
+	for _, key := range sopsKeys {
+		result = append(result, MasterKey{
+			Type:       key.TypeToIdentifier(),
+			Identifier: masterKeyIdentifier(key),
+		})
+	}
+
+	return result
+}
+
+// ReadMetadata returns the metadata of the given encrypted data using the specified format.
+func ReadMetadata(data []byte, format string) (Metadata, error) {
+	tree, err := loadEncryptedTree(data, format)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	m := tree.Metadata
+	metadata := Metadata{
+		LastModified:            m.LastModified,
+		Version:                 m.Version,
+		UnencryptedSuffix:       m.UnencryptedSuffix,
+		EncryptedSuffix:         m.EncryptedSuffix,
+		UnencryptedRegex:        m.UnencryptedRegex,
+		EncryptedRegex:          m.EncryptedRegex,
+		UnencryptedCommentRegex: m.UnencryptedCommentRegex,
+		EncryptedCommentRegex:   m.EncryptedCommentRegex,
+		MACOnlyEncrypted:        m.MACOnlyEncrypted,
+		MACPresent:              m.MessageAuthenticationCode != "",
+		ShamirThreshold:         m.ShamirThreshold,
+		KeyGroups:               make([][]MasterKey, 0, len(m.KeyGroups)),
+	}
+
+	for _, group := range m.KeyGroups {
+		metadata.KeyGroups = append(metadata.KeyGroups, masterKeys(group))
+	}
+
+	return metadata, nil
+}
+
+// ReadMetadataFile returns the metadata of the file at the given path using the specified format.
+func ReadMetadataFile(path string, format string) (Metadata, error) {
+	encryptedData, err := os.ReadFile(path)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	return ReadMetadata(encryptedData, format)
+}
@@ -0,0 +1,49 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadMetadataFileWithoutKeys(t *testing.T) {
+	// make sure no identities can be discovered from the environment
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_KEY_CMD", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	metadata, err := ReadMetadataFile(fixtureBasicYAMLFile, "yaml")
+	if err != nil {
+		t.Fatalf("ReadMetadataFile() error = %v", err)
+	}
+
+	if want := time.Date(2024, 11, 27, 20, 58, 6, 0, time.UTC); !metadata.LastModified.Equal(want) {
+		t.Errorf("LastModified = %v, want %v", metadata.LastModified, want)
+	}
+	if metadata.Version != "3.9.1" {
+		t.Errorf("Version = %q, want %q", metadata.Version, "3.9.1")
+	}
+	if metadata.UnencryptedSuffix != "_unencrypted" {
+		t.Errorf("UnencryptedSuffix = %q, want %q", metadata.UnencryptedSuffix, "_unencrypted")
+	}
+	if !metadata.MACPresent {
+		t.Error("MACPresent = false, want true")
+	}
+
+	want := MasterKey{Type: "age", Identifier: "age1cxgy6y5vctq5vy6dn4s3takyexa4n83cueuv8m0j0ngv2w6ff39qfguyjn"}
+	if len(metadata.KeyGroups) != 1 || len(metadata.KeyGroups[0]) != 1 || metadata.KeyGroups[0][0] != want {
+		t.Errorf("KeyGroups = %v, want [[%v]]", metadata.KeyGroups, want)
+	}
+}
+
+func TestReadMetadataRejectsPlaintext(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ReadMetadata([]byte("abc: xyz\n"), "yaml"); err == nil {
+		t.Fatal("ReadMetadata() error = nil, want error")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/getsops/sops/v3/aes"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+)
+
+// RotateDataKey decrypts the given data using the specified format and options and encrypts it
+// again with a freshly generated data key for the same master keys, like sops rotate. The plaintext
+// and the recipients of the returned document are unchanged.
+//
+// This function is mostly taken from the rotate command of the sops codebase, henceforth the
+// function is following the license of the sops codebase, i.e. MPL-2.0.
+func RotateDataKey(data []byte, format string, opts DecryptOptions) ([]byte, error) {
+	store := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decryptTree(&tree, opts, &DecryptResult{}); err != nil {
+		return nil, err
+	}
+
+	// generating a new data key encrypts it for all master keys of the tree again
+	dataKey, errs := tree.GenerateDataKeyWithKeyServices(opts.Keys.keyServices())
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to generate data key: %w", errors.Join(errs...))
+	}
+
+	err = common.EncryptTree(common.EncryptTreeOpts{
+		DataKey: dataKey,
+		Tree:    &tree,
+		Cipher:  aes.NewCipher(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store.EmitEncryptedFile(tree)
+}
+
+// RotateDataKeyFile rotates the data key of the file at the given path like RotateDataKey and
+// replaces the file atomically, keeping its permissions.
+func RotateDataKeyFile(path string, format string, opts DecryptOptions) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	rotated, err := RotateDataKey(data, format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := WriteFileAtomic(path, rotated, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+
+	return rotated, nil
+}
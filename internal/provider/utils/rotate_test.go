@@ -0,0 +1,111 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRotateDataKeyFile(t *testing.T) {
+	t.Parallel()
+
+	keys := mustNewKeysFromFile(t, testAgeKeyFile)
+	opts := DecryptOptions{Keys: keys}
+
+	original, err := os.ReadFile(fixtureBasicYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "basic.sops.yaml")
+	if err := os.WriteFile(path, original, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	rotated, err := RotateDataKeyFile(path, "yaml", opts)
+	if err != nil {
+		t.Fatalf("RotateDataKeyFile() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("RotateDataKeyFile() changed permissions to %v, want %v", info.Mode().Perm(), os.FileMode(0o600))
+	}
+
+	// the plaintext is unchanged
+	want, err := DecryptData(original, "yaml", opts)
+	if err != nil {
+		t.Fatalf("DecryptData() error = %v", err)
+	}
+
+	got, err := DecryptFile(path, "yaml", opts)
+	if err != nil {
+		t.Fatalf("DecryptFile() error = %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("RotateDataKeyFile() plaintext = %q, want %q", got, want)
+	}
+
+	// the recipients are unchanged, but the data key and the modification time are not
+	before, err := ReadMetadata(original, "yaml")
+	if err != nil {
+		t.Fatalf("ReadMetadata() error = %v", err)
+	}
+
+	after, err := ReadMetadata(rotated, "yaml")
+	if err != nil {
+		t.Fatalf("ReadMetadata() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(after.KeyGroups, before.KeyGroups) {
+		t.Errorf("RotateDataKeyFile() key groups = %+v, want %+v", after.KeyGroups, before.KeyGroups)
+	}
+
+	if !after.LastModified.After(before.LastModified) {
+		t.Errorf("RotateDataKeyFile() lastmodified = %v, want after %v", after.LastModified, before.LastModified)
+	}
+
+	if bytes.Equal(decryptedDataKey(t, original, opts), decryptedDataKey(t, rotated, opts)) {
+		t.Error("RotateDataKeyFile() did not generate a new data key")
+	}
+}
+
+func TestRotateDataKeyRejectsMACMismatch(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(fixtureBasicMACMismatchYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	if _, err := RotateDataKey(data, "yaml", DecryptOptions{Keys: mustNewKeysFromFile(t, testAgeKeyFile)}); err == nil {
+		t.Fatal("RotateDataKey() error = nil, want error")
+	}
+}
+
+// decryptedDataKey returns the data key of the given encrypted data.
+func decryptedDataKey(t *testing.T, data []byte, opts DecryptOptions) []byte {
+	t.Helper()
+
+	tree, err := loadEncryptedTree(data, "yaml")
+	if err != nil {
+		t.Fatalf("loadEncryptedTree() error = %v", err)
+	}
+
+	key, err := tree.Metadata.GetDataKeyWithKeyServices(opts.Keys.keyServices(), nil)
+	if err != nil {
+		t.Fatalf("GetDataKeyWithKeyServices() error = %v", err)
+	}
+
+	return key
+}
@@ -0,0 +1,145 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+)
+
+// keySelection is a node in the tree of selected key paths.
+type keySelection struct {
+	// all indicates that the whole value at this node is selected.
+	all bool
+	// found indicates that the node was found in the cleartext.
+	found bool
+	// children contains the selected keys below this node.
+	children map[string]*keySelection
+}
+
+// markFound marks the node and all selections below it as found, as they are contained in the
+// value selected by the node.
+func (s *keySelection) markFound() {
+	s.found = true
+	for _, child := range s.children {
+		child.markFound()
+	}
+}
+
+// SelectKeyPaths returns the given cleartext reduced to the values at the given paths, in the
+// sops --extract syntax or as JSON Pointers. The order of the remaining keys is preserved. Paths
+// can only address object keys, arrays can only be selected as a whole. For cleartexts with more
+// than one document, the paths are applied to every document and documents without any selected
+// value are dropped. Every path has to exist in at least one document.
+func SelectKeyPaths(cleartext []byte, format string, paths []string) ([]byte, error) {
+	if format == "binary" {
+		return nil, errors.New("selecting key paths is not supported for binary data")
+	}
+
+	root := &keySelection{}
+	leaves := make([]*keySelection, 0, len(paths))
+
+	for _, path := range paths {
+		segments, err := ParseExtractPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		node := root
+		for _, segment := range segments {
+			key, ok := segment.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid path %q: arrays can only be selected as a whole", path)
+			}
+
+			if node.children == nil {
+				node.children = map[string]*keySelection{}
+			}
+
+			if node.children[key] == nil {
+				node.children[key] = &keySelection{}
+			}
+
+			node = node.children[key]
+		}
+
+		node.all = true
+		leaves = append(leaves, node)
+	}
+
+	store := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+
+	branches, err := store.LoadPlainFile(cleartext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cleartext: %w", err)
+	}
+
+	var selected sops.TreeBranches
+	for _, branch := range branches {
+		filtered, err := selectBranch(branch, root, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(filtered) > 0 {
+			selected = append(selected, filtered)
+		}
+	}
+
+	for i, leaf := range leaves {
+		if !leaf.found {
+			return nil, fmt.Errorf("path not found: %s", paths[i])
+		}
+	}
+
+	return store.EmitPlainFile(selected)
+}
+
+// selectBranch returns the items of the given branch that are selected by the given node.
+func selectBranch(branch sops.TreeBranch, selection *keySelection, segments []any) (sops.TreeBranch, error) {
+	var selected sops.TreeBranch
+
+	for _, item := range branch {
+		// comments are stored as items of their own
+		if _, ok := item.Key.(sops.Comment); ok {
+			continue
+		}
+
+		key := fmt.Sprint(item.Key)
+
+		child := selection.children[key]
+		if child == nil {
+			continue
+		}
+
+		if child.all {
+			child.markFound()
+			selected = append(selected, item)
+			continue
+		}
+
+		child.found = true
+
+		childSegments := appendSegment(segments, key)
+
+		subtree, ok := item.Value.(sops.TreeBranch)
+		if !ok {
+			return nil, fmt.Errorf("path not found: %s is not an object, cannot select keys below it", formatPath(childSegments))
+		}
+
+		filtered, err := selectBranch(subtree, child, childSegments)
+		if err != nil {
+			return nil, err
+		}
+
+		selected = append(selected, sops.TreeItem{Key: item.Key, Value: filtered})
+	}
+
+	return selected, nil
+}
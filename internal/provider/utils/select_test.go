@@ -0,0 +1,95 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"testing"
+)
+
+func TestSelectKeyPaths(t *testing.T) {
+	t.Parallel()
+
+	cleartext := []byte("database:\n    user: admin\n    password: hunter2\n    hosts:\n        - db1\n        - db2\napi:\n    token: secret\nunrelated: value\n")
+
+	tests := []struct {
+		name   string
+		format string
+		data   []byte
+		paths  []string
+		want   string
+	}{
+		{
+			name:   "nested keys in source order",
+			format: "yaml",
+			data:   cleartext,
+			paths:  []string{`["api"]["token"]`, `/database/password`, `["database"]["user"]`},
+			want:   "database:\n    user: admin\n    password: hunter2\napi:\n    token: secret\n",
+		},
+		{
+			name:   "whole subtree",
+			format: "yaml",
+			data:   cleartext,
+			paths:  []string{`["database"]["hosts"]`, `["database"]`},
+			want:   "database:\n    user: admin\n    password: hunter2\n    hosts:\n        - db1\n        - db2\n",
+		},
+		{
+			name:   "dotenv",
+			format: "dotenv",
+			data:   []byte("USER=admin\nPASSWORD=hunter2\n"),
+			paths:  []string{`["PASSWORD"]`},
+			want:   "PASSWORD=hunter2\n",
+		},
+		{
+			name:   "multiple documents",
+			format: "yaml",
+			data:   []byte("a: 1\nb: 2\n---\nc: 3\n---\na: 4\n"),
+			paths:  []string{`["a"]`},
+			want:   "a: 1\n---\na: 4\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := SelectKeyPaths(tt.data, tt.format, tt.paths)
+			if err != nil {
+				t.Fatalf("SelectKeyPaths() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("SelectKeyPaths() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectKeyPathsErrors(t *testing.T) {
+	t.Parallel()
+
+	cleartext := []byte("database:\n    user: admin\n    hosts:\n        - db1\n")
+
+	tests := []struct {
+		name   string
+		format string
+		paths  []string
+	}{
+		{name: "missing key", format: "yaml", paths: []string{`["database"]["password"]`}},
+		{name: "array index", format: "yaml", paths: []string{`["database"]["hosts"][0]`}},
+		{name: "below array", format: "yaml", paths: []string{`/database/hosts/0`}},
+		{name: "below scalar", format: "yaml", paths: []string{`["database"]["user"]["name"]`}},
+		{name: "invalid path", format: "yaml", paths: []string{`database.user`}},
+		{name: "binary", format: "binary", paths: []string{`["data"]`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := SelectKeyPaths(cleartext, tt.format, tt.paths); err == nil {
+				t.Fatal("SelectKeyPaths() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+	sopsjson "github.com/getsops/sops/v3/stores/json"
+)
+
+// GetValue decrypts the given data using the specified format and options and returns the value
+// at the given path as normalized JSON, see NormalizeJSON. The path uses the syntax of
+// ParseExtractPath. If the path does not exist, found is false.
+func GetValue(data []byte, format string, path string, opts DecryptOptions) (value []byte, found bool, err error) {
+	doc, err := loadValueTree(data, format, path, opts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var node any = doc.tree.Branches[0]
+	for _, segment := range doc.segments {
+		switch n := node.(type) {
+		case sops.TreeBranch:
+			node, found = branchValue(n, segment)
+		case []any:
+			index := segment.(int)
+			if found = index < len(n); found {
+				node = n[index]
+			}
+		}
+
+		if !found {
+			return nil, false, nil
+		}
+	}
+
+	value, err = sopsjson.NewStore(&config.JSONStoreConfig{}).EmitValue(node)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, err = NormalizeJSON(value)
+	return value, true, err
+}
+
+// SetValue decrypts the given data using the specified format and options, sets the given JSON
+// value at the given path and encrypts the document again with the same data key, like sops set.
+// Missing objects along the path are created, all other values and the metadata of the document
+// are preserved and its MAC is recomputed. The path uses the syntax of ParseExtractPath, an array
+// index equal to the length of the array appends the value.
+func SetValue(data []byte, format string, path string, value []byte, opts DecryptOptions) ([]byte, error) {
+	leaf, err := treeValueFromJSON(value)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := loadValueTree(data, format, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.tree.Branches[0], _ = doc.tree.Branches[0].Set(doc.segments, leaf)
+
+	return doc.encrypt()
+}
+
+// UnsetValue decrypts the given data using the specified format and options, removes the value at
+// the given path and encrypts the document again with the same data key, like sops unset. If the
+// path does not exist, the data is returned unchanged and found is false.
+func UnsetValue(data []byte, format string, path string, opts DecryptOptions) (updated []byte, found bool, err error) {
+	doc, err := loadValueTree(data, format, path, opts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	branch, err := doc.tree.Branches[0].Unset(doc.segments)
+	if notFound := (*sops.SopsKeyNotFound)(nil); errors.As(err, &notFound) {
+		return data, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	doc.tree.Branches[0] = branch
+
+	updated, err = doc.encrypt()
+	return updated, true, err
+}
+
+// SetValueFile sets the value at the given key path of the file at the given path like SetValue
+// and replaces the file atomically, keeping its permissions. Callers should hold the lock of the
+// file, see LockFile.
+func SetValueFile(path string, format string, keyPath string, value []byte, opts DecryptOptions) ([]byte, error) {
+	return editFile(path, func(data []byte) ([]byte, error) {
+		return SetValue(data, format, keyPath, value, opts)
+	})
+}
+
+// UnsetValueFile removes the value at the given key path of the file at the given path like
+// UnsetValue and replaces the file atomically, keeping its permissions. The file is not written if
+// the key path does not exist. Callers should hold the lock of the file, see LockFile.
+func UnsetValueFile(path string, format string, keyPath string, opts DecryptOptions) ([]byte, error) {
+	return editFile(path, func(data []byte) ([]byte, error) {
+		updated, found, err := UnsetValue(data, format, keyPath, opts)
+		if err != nil || !found {
+			return nil, err
+		}
+
+		return updated, nil
+	})
+}
+
+// NormalizeJSON returns the given JSON value re-encoded without insignificant whitespace and with
+// the keys of objects sorted, so that equal values have an equal representation. Numbers are
+// preserved as is.
+func NormalizeJSON(value []byte) ([]byte, error) {
+	decoded, err := decodeJSONPreservingNumbers(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON value: %w", err)
+	}
+
+	return json.Marshal(decoded)
+}
+
+// editFile replaces the file at the given path atomically with the result of edit, keeping its
+// permissions. If edit returns nil, the file is left untouched and its current content returned.
+func editFile(path string, edit func(data []byte) ([]byte, error)) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	updated, err := edit(data)
+	if err != nil || updated == nil {
+		return data, err
+	}
+
+	if err := WriteFileAtomic(path, updated, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// valueTree is a decrypted sops document whose value at a path is read or modified.
+type valueTree struct {
+	store common.Store
+	tree  sops.Tree
+	key   []byte
+	// segments is the resolved path to pass to the sops tree functions, see resolvePath.
+	segments []any
+}
+
+// loadValueTree loads and decrypts the given data and resolves the given path against its first
+// document.
+func loadValueTree(data []byte, format string, path string, opts DecryptOptions) (valueTree, error) {
+	if formats.FormatFromString(format) == formats.Binary {
+		return valueTree{}, errors.New("values cannot be addressed in binary files")
+	}
+
+	segments, err := ParseExtractPath(path)
+	if err != nil {
+		return valueTree{}, err
+	}
+
+	doc := valueTree{store: common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())}
+
+	doc.tree, err = doc.store.LoadEncryptedFile(data)
+	if err != nil {
+		return valueTree{}, err
+	}
+
+	doc.key, err = decryptTree(&doc.tree, opts, &DecryptResult{})
+	if err != nil {
+		return valueTree{}, err
+	}
+
+	if len(doc.tree.Branches) == 0 {
+		doc.tree.Branches = sops.TreeBranches{sops.TreeBranch{}}
+	}
+
+	doc.segments, err = resolvePath(doc.tree.Branches[0], segments)
+	if err != nil {
+		return valueTree{}, err
+	}
+
+	return doc, nil
+}
+
+// encrypt encrypts the tree again with its original data key, recomputing its MAC, and emits it.
+func (doc valueTree) encrypt() ([]byte, error) {
+	err := common.EncryptTree(common.EncryptTreeOpts{
+		DataKey: doc.key,
+		Tree:    &doc.tree,
+		Cipher:  aes.NewCipher(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.store.EmitEncryptedFile(doc.tree)
+}
+
+// resolvePath returns the given path segments with the segments addressing array elements
+// converted to ints, as JSON Pointer segments are always strings. Segments below the existing part
+// of the document are returned as is, so that setting a value creates objects for them.
+func resolvePath(branch sops.TreeBranch, segments []any) ([]any, error) {
+	resolved := make([]any, 0, len(segments))
+
+	var node any = branch
+	for i, segment := range segments {
+		parent := formatPath(segments[:i])
+		if parent == "" {
+			parent = "the document root"
+		}
+
+		switch n := node.(type) {
+		case sops.TreeBranch:
+			key, ok := segment.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid path: %s is an object, cannot index it with [%d]", parent, segment)
+			}
+
+			resolved = append(resolved, key)
+
+			var found bool
+			if node, found = branchValue(n, key); !found {
+				return append(resolved, segments[i+1:]...), nil
+			}
+		case []any:
+			index, ok := segment.(int)
+			if !ok {
+				var err error
+				if index, err = strconv.Atoi(segment.(string)); err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid path: %s is an array, cannot index it with %q", parent, segment)
+				}
+			}
+
+			if index > len(n) {
+				return nil, fmt.Errorf("invalid path: index %d is out of range of %s with %d elements", index, parent, len(n))
+			}
+
+			resolved = append(resolved, index)
+
+			if index == len(n) {
+				return append(resolved, segments[i+1:]...), nil
+			}
+
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("invalid path: %s is a scalar value, cannot descend into %s", parent, formatPath(segments[i:i+1]))
+		}
+	}
+
+	return resolved, nil
+}
+
+// branchValue returns the value of the given key of the branch.
+func branchValue(branch sops.TreeBranch, key any) (any, bool) {
+	for _, item := range branch {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// treeValueFromJSON converts the given JSON value into a value of a sops tree, keeping the order
+// of object keys.
+func treeValueFromJSON(value []byte) (any, error) {
+	if _, err := NormalizeJSON(value); err != nil {
+		return nil, err
+	}
+
+	// the JSON store can only load objects, so the value is wrapped into one
+	wrapped := append(append([]byte(`{"value":`), value...), '}')
+
+	branches, err := sopsjson.NewStore(&config.JSONStoreConfig{}).LoadPlainFile(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON value: %w", err)
+	}
+
+	return branches[0][0].Value, nil
+}
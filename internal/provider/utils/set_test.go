@@ -0,0 +1,240 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetValue(t *testing.T) {
+	t.Parallel()
+
+	opts := DecryptOptions{Keys: mustNewKeysFromFile(t, testAgeKeyFile)}
+
+	original, err := os.ReadFile(fixtureBasicYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		value     string
+		wantValue string
+		want      string
+	}{
+		{
+			name:      "new nested key",
+			path:      `["database"]["password"]`,
+			value:     `"hunter2"`,
+			wantValue: `"hunter2"`,
+			want:      "abc: xyz\nintegers: 123\ntruthy: true\nfloats: 3.14e-10\ndatabase:\n    password: hunter2\n",
+		},
+		{
+			name:      "existing key",
+			path:      `/integers`,
+			value:     `{"b": 1, "a": [true, null]}`,
+			wantValue: `{"a":[true,null],"b":1}`,
+			want:      "abc: xyz\nintegers:\n    b: 1\n    a:\n        - true\n        - null\ntruthy: true\nfloats: 3.14e-10\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			updated, err := SetValue(original, "yaml", test.path, []byte(test.value), opts)
+			if err != nil {
+				t.Fatalf("SetValue() error = %v", err)
+			}
+
+			// decrypting verifies the recomputed MAC
+			got, err := DecryptData(updated, "yaml", opts)
+			if err != nil {
+				t.Fatalf("DecryptData() error = %v", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("SetValue() plaintext = %q, want %q", got, test.want)
+			}
+
+			value, found, err := GetValue(updated, "yaml", test.path, opts)
+			if err != nil || !found {
+				t.Fatalf("GetValue() = (%s, %v, %v), want found", value, found, err)
+			}
+
+			if string(value) != test.wantValue {
+				t.Errorf("GetValue() = %s, want %s", value, test.wantValue)
+			}
+
+			// the metadata and the data key are preserved
+			before, _ := ReadMetadata(original, "yaml")
+			after, _ := ReadMetadata(updated, "yaml")
+			if !reflect.DeepEqual(after.KeyGroups, before.KeyGroups) {
+				t.Errorf("SetValue() key groups = %+v, want %+v", after.KeyGroups, before.KeyGroups)
+			}
+
+			if !bytes.Equal(decryptedDataKey(t, updated, opts), decryptedDataKey(t, original, opts)) {
+				t.Error("SetValue() changed the data key")
+			}
+		})
+	}
+}
+
+func TestSetValueErrors(t *testing.T) {
+	t.Parallel()
+
+	opts := DecryptOptions{Keys: mustNewKeysFromFile(t, testAgeKeyFile)}
+
+	original, err := os.ReadFile(fixtureBasicYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	withList, err := SetValue(original, "yaml", `["hosts"]`, []byte(`["a", "b"]`), opts)
+	if err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		format  string
+		path    string
+		value   string
+		wantErr string
+	}{
+		{name: "scalar", data: original, format: "yaml", path: `["abc"]["x"]`, value: `1`, wantErr: `["abc"] is a scalar value`},
+		{name: "index of object", data: original, format: "yaml", path: `[0]`, value: `1`, wantErr: `the document root is an object`},
+		{name: "index out of range", data: withList, format: "yaml", path: `/hosts/3`, value: `"c"`, wantErr: `index 3 is out of range`},
+		{name: "invalid JSON", data: original, format: "yaml", path: `/abc`, value: `hunter2`, wantErr: `invalid JSON value`},
+		{name: "binary", data: original, format: "binary", path: `["data"]`, value: `"x"`, wantErr: `binary files`},
+		{name: "MAC mismatch", data: mustReadFile(t, fixtureBasicMACMismatchYAMLFile), format: "yaml", path: `/abc`, value: `"x"`, wantErr: `failed to verify data integrity`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := SetValue(test.data, test.format, test.path, []byte(test.value), opts)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("SetValue() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+
+	// appending to an array is allowed
+	appended, err := SetValue(withList, "yaml", `/hosts/2`, []byte(`"c"`), opts)
+	if err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+
+	if value, _, _ := GetValue(appended, "yaml", `["hosts"]`, opts); string(value) != `["a","b","c"]` {
+		t.Errorf("GetValue() = %s, want %s", value, `["a","b","c"]`)
+	}
+}
+
+func TestUnsetValue(t *testing.T) {
+	t.Parallel()
+
+	opts := DecryptOptions{Keys: mustNewKeysFromFile(t, testAgeKeyFile)}
+
+	original, err := os.ReadFile(fixtureBasicYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	updated, found, err := UnsetValue(original, "yaml", `["integers"]`, opts)
+	if err != nil || !found {
+		t.Fatalf("UnsetValue() = (%v, %v), want found", found, err)
+	}
+
+	got, err := DecryptData(updated, "yaml", opts)
+	if err != nil {
+		t.Fatalf("DecryptData() error = %v", err)
+	}
+
+	if want := "abc: xyz\ntruthy: true\nfloats: 3.14e-10\n"; string(got) != want {
+		t.Errorf("UnsetValue() plaintext = %q, want %q", got, want)
+	}
+
+	if _, found, err := GetValue(updated, "yaml", `["integers"]`, opts); err != nil || found {
+		t.Errorf("GetValue() = (%v, %v), want not found", found, err)
+	}
+
+	// a missing path leaves the data untouched
+	for _, path := range []string{`["integers"]`, `["missing"]["nested"]`} {
+		unchanged, found, err := UnsetValue(updated, "yaml", path, opts)
+		if err != nil || found {
+			t.Fatalf("UnsetValue(%s) = (%v, %v), want not found", path, found, err)
+		}
+
+		if !bytes.Equal(unchanged, updated) {
+			t.Errorf("UnsetValue(%s) changed the data", path)
+		}
+	}
+}
+
+func TestSetValueFileConcurrent(t *testing.T) {
+	t.Parallel()
+
+	opts := DecryptOptions{Keys: mustNewKeysFromFile(t, testAgeKeyFile)}
+
+	path := filepath.Join(t.TempDir(), "basic.sops.yaml")
+	if err := os.WriteFile(path, mustReadFile(t, fixtureBasicYAMLFile), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			unlock, err := LockFile(path)
+			if err != nil {
+				t.Errorf("LockFile() error = %v", err)
+				return
+			}
+			defer unlock()
+
+			if _, err := SetValueFile(path, "yaml", fmt.Sprintf(`["key%d"]`, i), []byte(`"value"`), opts); err != nil {
+				t.Errorf("SetValueFile() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	data := mustReadFile(t, path)
+	for i := range 10 {
+		if _, found, err := GetValue(data, "yaml", fmt.Sprintf(`["key%d"]`, i), opts); err != nil || !found {
+			t.Errorf("GetValue(key%d) = (%v, %v), want found", i, found, err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("SetValueFile() changed permissions to %v, want %v", info.Mode().Perm(), os.FileMode(0o600))
+	}
+}
+
+// mustReadFile returns the content of the file at the given path.
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	return data
+}
@@ -0,0 +1,74 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Command age-plugin-fake is an age plugin for tests. Its identities wrap an age X25519 identity,
+// prefixed with a mode and a colon: "ok" unwraps file keys right away, "pin" requests a PIN first,
+// like a hardware token would, "hang" never responds, like a plugin waiting for a hardware token to
+// be touched, and "crash" exits with an error message, like a plugin that cannot find its token.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/plugin"
+)
+
+func main() {
+	p, err := plugin.New("fake")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	p.HandleIdentity(func(data []byte) (age.Identity, error) {
+		mode, identity, ok := strings.Cut(string(data), ":")
+		if !ok {
+			return nil, errors.New("missing mode")
+		}
+
+		parsed, err := age.ParseX25519Identity(identity)
+		if err != nil {
+			return nil, err
+		}
+
+		return &fakeIdentity{plugin: p, mode: mode, identity: parsed}, nil
+	})
+
+	os.Exit(p.Main())
+}
+
+type fakeIdentity struct {
+	plugin   *plugin.Plugin
+	mode     string
+	identity *age.X25519Identity
+}
+
+func (i *fakeIdentity) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	switch i.mode {
+	case "pin":
+		pin, err := i.plugin.RequestValue("Enter PIN for fake token", true)
+		if err != nil {
+			return nil, err
+		}
+
+		if pin != "123456" {
+			return nil, errors.New("wrong PIN")
+		}
+	case "hang":
+		time.Sleep(time.Hour)
+	case "crash":
+		fmt.Fprintln(os.Stderr, "fake token not connected")
+		os.Exit(2)
+	}
+
+	if err := i.plugin.DisplayMessage("unwrapping with fake token"); err != nil {
+		return nil, err
+	}
+
+	return i.identity.Unwrap(stanzas)
+}
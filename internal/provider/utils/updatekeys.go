@@ -0,0 +1,129 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+)
+
+// UpdateKeysResult describes the changes UpdateKeys made to the key groups of a document.
+type UpdateKeysResult struct {
+	// Changed is true if the key groups or the Shamir threshold of the document changed.
+	Changed bool
+	// Added are the master keys the data key was encrypted for in addition.
+	Added []MasterKey
+	// Removed are the master keys that can no longer decrypt the data key.
+	Removed []MasterKey
+}
+
+// UpdateKeys replaces the key groups of the given encrypted data using the specified format with
+// the given key groups, like sops updatekeys. The data key is decrypted with the key material of
+// the options and encrypted again for the new master keys, the encrypted values and the data key
+// itself are not changed. The returned data is nil if the key groups are already up to date.
+//
+// This function is mostly taken from the updatekeys command of the sops codebase, henceforth the
+// function is following the license of the sops codebase, i.e. MPL-2.0.
+func UpdateKeys(data []byte, format string, keyGroups []sops.KeyGroup, opts DecryptOptions) ([]byte, UpdateKeysResult, error) {
+	var result UpdateKeysResult
+
+	store := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+
+	tree, err := store.LoadEncryptedFile(data)
+	if err != nil {
+		return nil, result, err
+	}
+
+	for _, diff := range common.DiffKeyGroups(tree.Metadata.KeyGroups, keyGroups) {
+		result.Added = append(result.Added, masterKeys(diff.Added)...)
+		result.Removed = append(result.Removed, masterKeys(diff.Removed)...)
+	}
+
+	shamirThreshold := min(tree.Metadata.ShamirThreshold, len(keyGroups))
+	result.Changed = len(result.Added) > 0 || len(result.Removed) > 0 || shamirThreshold != tree.Metadata.ShamirThreshold
+
+	if !result.Changed {
+		return nil, result, nil
+	}
+
+	if err := opts.Keys.err(); err != nil {
+		return nil, result, err
+	}
+
+	key, err := tree.Metadata.GetDataKeyWithKeyServices(opts.Keys.keyServices(), nil)
+	if err != nil {
+		return nil, result, err
+	}
+
+	tree.Metadata.KeyGroups = keyGroups
+	tree.Metadata.ShamirThreshold = shamirThreshold
+
+	if errs := tree.Metadata.UpdateMasterKeysWithKeyServices(key, opts.Keys.keyServices()); len(errs) > 0 {
+		return nil, result, fmt.Errorf("failed to update master keys: %w", errors.Join(errs...))
+	}
+
+	updated, err := store.EmitEncryptedFile(tree)
+	if err != nil {
+		return nil, result, err
+	}
+
+	return updated, result, nil
+}
+
+// UpdateKeysFile updates the key groups of the file at the given path like UpdateKeys, using the
+// key groups of the creation rule matching the file in the sops config file at configPath. If
+// configPath is empty, the config file is looked up in the directory of the file and its parents,
+// like sops does. The file is only replaced, atomically and keeping its permissions, if its key
+// groups changed.
+func UpdateKeysFile(path string, format string, configPath string, opts DecryptOptions) (UpdateKeysResult, error) {
+	// creation rules are matched against the path relative to the config file
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return UpdateKeysResult{}, err
+	}
+
+	if configPath == "" {
+		configPath, err = config.FindConfigFile(absPath)
+		if err != nil {
+			return UpdateKeysResult{}, fmt.Errorf("failed to find sops config file for %q: %w", path, err)
+		}
+	}
+
+	conf, err := config.LoadCreationRuleForFile(configPath, absPath, map[string]*string{})
+	if err != nil {
+		return UpdateKeysResult{}, fmt.Errorf("failed to load creation rule from %q: %w", configPath, err)
+	}
+
+	if conf == nil || len(conf.KeyGroups) == 0 {
+		return UpdateKeysResult{}, fmt.Errorf("config file %q does not contain any keys for %q", configPath, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return UpdateKeysResult{}, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return UpdateKeysResult{}, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	updated, result, err := UpdateKeys(data, format, conf.KeyGroups, opts)
+	if err != nil || !result.Changed {
+		return result, err
+	}
+
+	if err := WriteFileAtomic(path, updated, info.Mode().Perm()); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
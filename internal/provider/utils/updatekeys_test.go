@@ -0,0 +1,179 @@
+// Copyright Alexej Disterhoft <alexej@disterhoft.de> 2024, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testOtherAgeRecipient = "age1y6ca38htznet3puh3vcwfqmymhxqtlnrkf5hygt8lxpsnfx5zuzq5wsrfz"
+
+func TestUpdateKeysFile(t *testing.T) {
+	t.Parallel()
+
+	keys := mustNewKeysFromFile(t, testAgeKeyFile)
+	opts := DecryptOptions{Keys: keys}
+
+	original, err := os.ReadFile(fixtureBasicYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets", "basic.sops.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("os.MkdirAll() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, original, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	// the config file is looked up in the parent directories of the file
+	writeSopsConfig(t, filepath.Join(dir, ".sops.yaml"), `
+creation_rules:
+  - path_regex: ^secrets/.*\.sops\.yaml$
+    age: `+testAgeRecipient+`,`+testOtherAgeRecipient+`
+`)
+
+	result, err := UpdateKeysFile(path, "yaml", "", opts)
+	if err != nil {
+		t.Fatalf("UpdateKeysFile() error = %v", err)
+	}
+
+	want := UpdateKeysResult{Changed: true, Added: []MasterKey{{Type: "age", Identifier: testOtherAgeRecipient}}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("UpdateKeysFile() = %+v, want %+v", result, want)
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	// the plaintext and the data key are unchanged
+	plaintext, err := DecryptData(original, "yaml", opts)
+	if err != nil {
+		t.Fatalf("DecryptData() error = %v", err)
+	}
+
+	got, err := DecryptData(updated, "yaml", opts)
+	if err != nil {
+		t.Fatalf("DecryptData() error = %v", err)
+	}
+
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("UpdateKeysFile() plaintext = %q, want %q", got, plaintext)
+	}
+
+	if !bytes.Equal(decryptedDataKey(t, updated, opts), decryptedDataKey(t, original, opts)) {
+		t.Error("UpdateKeysFile() changed the data key")
+	}
+
+	// updating the keys again does not change the file
+	result, err = UpdateKeysFile(path, "yaml", "", opts)
+	if err != nil {
+		t.Fatalf("UpdateKeysFile() error = %v", err)
+	}
+
+	if result.Changed {
+		t.Errorf("UpdateKeysFile() = %+v, want unchanged", result)
+	}
+
+	// an explicit config file takes precedence over the one found next to the file
+	configPath := filepath.Join(dir, "other.sops.yaml")
+	writeSopsConfig(t, configPath, `
+creation_rules:
+  - age: `+testOtherAgeRecipient+`
+`)
+
+	result, err = UpdateKeysFile(path, "yaml", configPath, opts)
+	if err != nil {
+		t.Fatalf("UpdateKeysFile() error = %v", err)
+	}
+
+	want = UpdateKeysResult{Changed: true, Removed: []MasterKey{{Type: "age", Identifier: testAgeRecipient}}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("UpdateKeysFile() = %+v, want %+v", result, want)
+	}
+
+	metadata, err := ReadMetadataFile(path, "yaml")
+	if err != nil {
+		t.Fatalf("ReadMetadataFile() error = %v", err)
+	}
+
+	wantKeyGroups := [][]MasterKey{{{Type: "age", Identifier: testOtherAgeRecipient}}}
+	if !reflect.DeepEqual(metadata.KeyGroups, wantKeyGroups) {
+		t.Errorf("UpdateKeysFile() key groups = %+v, want %+v", metadata.KeyGroups, wantKeyGroups)
+	}
+}
+
+func TestUpdateKeysFileErrors(t *testing.T) {
+	t.Parallel()
+
+	opts := DecryptOptions{Keys: mustNewKeysFromFile(t, testAgeKeyFile)}
+
+	original, err := os.ReadFile(fixtureBasicYAMLFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "basic.sops.yaml")
+	if err := os.WriteFile(path, original, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+
+	noMatch := filepath.Join(dir, "nomatch.sops.yaml")
+	writeSopsConfig(t, noMatch, `
+creation_rules:
+  - path_regex: ^prod/
+    age: `+testOtherAgeRecipient+`
+`)
+
+	noKeys := filepath.Join(dir, "nokeys.sops.yaml")
+	writeSopsConfig(t, noKeys, `
+creation_rules:
+  - path_regex: .*
+`)
+
+	tests := []struct {
+		name       string
+		configPath string
+	}{
+		{name: "no matching creation rule", configPath: noMatch},
+		{name: "creation rule without keys", configPath: noKeys},
+		{name: "missing config file", configPath: filepath.Join(dir, "missing.sops.yaml")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := UpdateKeysFile(path, "yaml", test.configPath, opts); err == nil {
+				t.Fatal("UpdateKeysFile() error = nil, want error")
+			}
+		})
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+
+	if !bytes.Equal(got, original) {
+		t.Error("UpdateKeysFile() modified the file on error")
+	}
+}
+
+// writeSopsConfig writes the given sops config to the given path.
+func writeSopsConfig(t *testing.T, path string, config string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+}